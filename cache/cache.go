@@ -0,0 +1,88 @@
+// Package cache stores review findings on disk so unchanged files are not reviewed twice.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/revrost/glimpse/review"
+)
+
+// Store keeps one JSON file of findings per key, sharded by the key's first
+// two characters. It is safe for concurrent use, also across processes.
+type Store struct {
+	dir string
+}
+
+// Open returns the store in dir; the directory is created on the first write
+func Open(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the store directory
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Get returns the findings stored under key. Unreadable entries are misses.
+func (s *Store) Get(key string) ([]review.Finding, bool) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var findings []review.Finding
+	if err := json.Unmarshal(data, &findings); err != nil || findings == nil {
+		return nil, false
+	}
+	return findings, true
+}
+
+// Put stores findings under key, replacing any previous entry
+func (s *Store) Put(key string, findings []review.Finding) error {
+	if findings == nil {
+		findings = []review.Finding{}
+	}
+	data, err := json.Marshal(findings)
+	if err != nil {
+		return fmt.Errorf("failed to encode findings: %w", err)
+	}
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write then rename, so readers never see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every stored entry
+func (s *Store) Clear() error {
+	return os.RemoveAll(s.dir)
+}
+
+// path returns the file of a key
+func (s *Store) path(key string) string {
+	shard := key
+	if len(shard) > 2 {
+		shard = shard[:2]
+	}
+	return filepath.Join(s.dir, shard, key+".json")
+}
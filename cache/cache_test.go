@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/revrost/glimpse/review"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorePutAndGet(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "cache"))
+
+	_, ok := store.Get("abcdef")
+	assert.False(t, ok, "missing entry is a miss")
+
+	assert.NoError(t, store.Put("abcdef", []review.Finding{{File: "a.go", Message: "m"}}))
+	findings, ok := store.Get("abcdef")
+	assert.True(t, ok)
+	assert.Equal(t, "m", findings[0].Message)
+	assert.FileExists(t, filepath.Join(store.Dir(), "ab", "abcdef.json"))
+
+	// A clean file is cached too
+	assert.NoError(t, store.Put("012345", nil))
+	findings, ok = store.Get("012345")
+	assert.True(t, ok)
+	assert.Empty(t, findings)
+
+	assert.NoError(t, store.Clear())
+	_, ok = store.Get("abcdef")
+	assert.False(t, ok)
+}
+
+func TestStoreIgnoresCorruptEntries(t *testing.T) {
+	store := Open(t.TempDir())
+	assert.NoError(t, os.MkdirAll(filepath.Join(store.Dir(), "ab"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(store.Dir(), "ab", "abcdef.json"), []byte("{trunc"), 0644))
+
+	_, ok := store.Get("abcdef")
+	assert.False(t, ok)
+}
+
+func TestStoreConcurrentPuts(t *testing.T) {
+	store := Open(t.TempDir())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, store.Put("abcdef", []review.Finding{{Message: "m"}}))
+		}()
+	}
+	wg.Wait()
+
+	findings, ok := store.Get("abcdef")
+	assert.True(t, ok)
+	assert.Len(t, findings, 1)
+	entries, err := os.ReadDir(filepath.Join(store.Dir(), "ab"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files left behind")
+}
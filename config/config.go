@@ -11,12 +11,26 @@ import (
 	"github.com/revrost/glimpse/styles"
 )
 
+// Defaults for settings that must not be zero
+const (
+	DefaultMaxRetries     = 3     // Retries for transient LLM API failures
+	DefaultMaxChunkTokens = 24000 // Token budget of a single review request
+	DefaultConcurrency    = 4     // Review requests in flight at once
+	DefaultHistoryEntries = 500   // Reviews kept in the history
+)
+
 // Config holds the complete application configuration
 type Config struct {
-	Watch  []string   `yaml:"watch"`
-	Ignore []string   `yaml:"ignore"`
-	Logs   LogsConfig `yaml:"logs"`
-	LLM    LLMConfig  `yaml:"llm"`
+	Watch   []string      `yaml:"watch"`
+	Ignore  []string      `yaml:"ignore"`
+	Logs    LogsConfig    `yaml:"logs"`
+	LLM     LLMConfig     `yaml:"llm"`
+	Review  ReviewConfig  `yaml:"review"`
+	Redact  RedactConfig  `yaml:"redact"`
+	Hook    HookConfig    `yaml:"hook"`
+	History HistoryConfig `yaml:"history"`
+	Cache   CacheConfig   `yaml:"cache"`
+	Fix     FixConfig     `yaml:"fix"`
 }
 
 // LogsConfig holds log scraping configuration
@@ -31,6 +45,71 @@ type LLMConfig struct {
 	Model        string `yaml:"model"`
 	APIKey       string `yaml:"api_key"`
 	SystemPrompt string `yaml:"system_prompt"`
+	BaseURL      string `yaml:"base_url,omitempty"` // Custom endpoint for self-hosted or proxied models
+	MaxRetries   int    `yaml:"max_retries"`        // Retries on rate limits and server errors (0 disables)
+}
+
+// ReviewConfig controls which review findings are reported
+type ReviewConfig struct {
+	MinSeverity    string   `yaml:"min_severity"`         // critical, high, medium, low or info
+	Categories     []string `yaml:"categories,omitempty"` // Empty reports every category
+	MaxChunkTokens int      `yaml:"max_chunk_tokens"`     // Larger changes are split into several requests
+	Concurrency    int      `yaml:"concurrency"`          // Chunks reviewed in parallel
+	SkipGenerated  bool     `yaml:"skip_generated"`       // Skip lockfiles, vendored code and generated protobuf
+	Incremental    bool     `yaml:"incremental"`          // Staged reviews only send hunks changed since the previous one (needs history)
+}
+
+// RedactConfig controls masking of secrets and PII before anything is sent to the LLM
+type RedactConfig struct {
+	Enabled       bool            `yaml:"enabled"`
+	Entropy       bool            `yaml:"entropy"`         // Mask high-entropy strings that look like keys
+	BlockOnSecret bool            `yaml:"block_on_secret"` // Skip the review when a secret is staged
+	Patterns      []RedactPattern `yaml:"patterns,omitempty"`
+}
+
+// RedactPattern is a user-defined redaction regex
+type RedactPattern struct {
+	Name  string `yaml:"name"`
+	Regex string `yaml:"regex"`
+	Kind  string `yaml:"kind,omitempty"` // secret (default) or pii
+}
+
+// HookConfig controls the git pre-commit and pre-push hooks
+type HookConfig struct {
+	FailOn string `yaml:"fail_on"` // Findings at or above this severity fail the hook
+}
+
+// HistoryConfig controls the local store of past reviews
+type HistoryConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	MaxEntries int    `yaml:"max_entries"`   // Oldest reviews are dropped beyond this (0 keeps all)
+	Dir        string `yaml:"dir,omitempty"` // Defaults to .git/glimpse, or the XDG data dir outside a repository
+}
+
+// CacheConfig controls reuse of the findings of unchanged files
+type CacheConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir,omitempty"` // Defaults to .git/glimpse/cache, or the XDG cache dir outside a repository
+}
+
+// FixConfig selects the agent that fixes findings in fix mode (-f)
+type FixConfig struct {
+	Agent  string                 `yaml:"agent"`            // crush (default), aider, opencode, native or a name under agents
+	Agents map[string]AgentConfig `yaml:"agents,omitempty"` // Custom agents; a built-in name overrides its profile
+	// Verify are shell commands run after a fix, e.g. "go build ./..."; failures go back to the fixer
+	Verify  []string `yaml:"verify,omitempty"`
+	Retries int      `yaml:"retries"` // Further fix attempts while verify fails
+}
+
+// AgentConfig is a fix agent profile
+type AgentConfig struct {
+	Command []string `yaml:"command,omitempty"` // Program and arguments; {prompt} and {file} are replaced
+	Prompt  string   `yaml:"prompt,omitempty"`  // How the prompt is passed: argv (default), stdin or file
+	Timeout string   `yaml:"timeout,omitempty"` // e.g. "10m" (default 5m)
+	// MaxPrompt is the longest argv prompt in characters (default 10000);
+	// LongPrompt passes longer ones: file (default), stdin or truncate
+	MaxPrompt  int    `yaml:"max_prompt,omitempty"`
+	LongPrompt string `yaml:"long_prompt,omitempty"`
 }
 
 // getGlobalConfigPath returns the path to the global config file following XDG convention
@@ -102,6 +181,32 @@ func Load() (*Config, error) {
 			Provider:     "",  // Empty default to trigger prompting
 			Model:        "",  // Empty default to trigger prompting
 			SystemPrompt: "You are a Principal Go Engineer. Review strictly for bugs, perf, and slog context.",
+			MaxRetries:   DefaultMaxRetries,
+		},
+		Review: ReviewConfig{
+			MinSeverity:    "low",
+			MaxChunkTokens: DefaultMaxChunkTokens,
+			Concurrency:    DefaultConcurrency,
+			SkipGenerated:  true,
+			Incremental:    true,
+		},
+		Redact: RedactConfig{
+			Enabled: true,
+			Entropy: true,
+		},
+		Hook: HookConfig{
+			FailOn: "high",
+		},
+		History: HistoryConfig{
+			Enabled:    true,
+			MaxEntries: DefaultHistoryEntries,
+		},
+		Cache: CacheConfig{
+			Enabled: true,
+		},
+		Fix: FixConfig{
+			Agent:   "crush",
+			Retries: 2,
 		},
 	}
 
@@ -140,21 +245,29 @@ func Load() (*Config, error) {
 
 	// Get API key from environment if not in config
 	if config.LLM.APIKey == "" {
-		switch config.LLM.Provider {
-		case "openai":
-			config.LLM.APIKey = os.Getenv("OPENAI_API_KEY")
-		case "gemini":
-			config.LLM.APIKey = os.Getenv("GEMINI_API_KEY")
-		case "zai":
-			config.LLM.APIKey = os.Getenv("ZAI_API_KEY")
-		case "claude":
-			config.LLM.APIKey = os.Getenv("ANTHROPIC_API_KEY")
-		}
+		config.LLM.APIKey = APIKeyFromEnv(config.LLM.Provider)
 	}
 
 	return config, nil
 }
 
+// APIKeyFromEnv returns the API key for a provider from its environment variable
+func APIKeyFromEnv(provider string) string {
+	switch provider {
+	case "openai":
+		return os.Getenv("OPENAI_API_KEY")
+	case "openai-compatible":
+		return os.Getenv("OPENAI_COMPATIBLE_API_KEY")
+	case "gemini":
+		return os.Getenv("GEMINI_API_KEY")
+	case "zai":
+		return os.Getenv("ZAI_API_KEY")
+	case "claude":
+		return os.Getenv("ANTHROPIC_API_KEY")
+	}
+	return ""
+}
+
 // PromptAndSaveProvider prompts the user to select a provider and saves it to global config
 func PromptAndSaveProvider() error {
 	// Prompt for provider selection
@@ -188,6 +301,32 @@ func PromptAndSaveProvider() error {
 			Provider:     provider,
 			Model:        model,
 			SystemPrompt: "You are a Principal Go Engineer. Review strictly for bugs, perf, and slog context.",
+			MaxRetries:   DefaultMaxRetries,
+		},
+		Review: ReviewConfig{
+			MinSeverity:    "low",
+			MaxChunkTokens: DefaultMaxChunkTokens,
+			Concurrency:    DefaultConcurrency,
+			SkipGenerated:  true,
+			Incremental:    true,
+		},
+		Redact: RedactConfig{
+			Enabled: true,
+			Entropy: true,
+		},
+		Hook: HookConfig{
+			FailOn: "high",
+		},
+		History: HistoryConfig{
+			Enabled:    true,
+			MaxEntries: DefaultHistoryEntries,
+		},
+		Cache: CacheConfig{
+			Enabled: true,
+		},
+		Fix: FixConfig{
+			Agent:   "crush",
+			Retries: 2,
 		},
 	}
 	
@@ -62,4 +62,31 @@ func TestGetDebounceDuration(t *testing.T) {
 	config := &Config{}
 	duration := config.GetDebounceDuration()
 	assert.Equal(t, "500ms", duration.String())
-}
\ No newline at end of file
+}
+
+func TestLoadBaseURL(t *testing.T) {
+	configContent := `
+llm:
+  provider: "openai-compatible"
+  model: "qwen2.5-coder"
+  base_url: "http://localhost:8000/v1"
+`
+	err := os.WriteFile(".glimpse.yaml", []byte(configContent), 0644)
+	assert.NoError(t, err)
+	defer os.Remove(".glimpse.yaml")
+
+	t.Setenv("OPENAI_COMPATIBLE_API_KEY", "local-key")
+
+	config, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "openai-compatible", config.LLM.Provider)
+	assert.Equal(t, "http://localhost:8000/v1", config.LLM.BaseURL)
+	assert.Equal(t, "local-key", config.LLM.APIKey)
+	assert.Equal(t, DefaultMaxRetries, config.LLM.MaxRetries)
+}
+
+func TestAPIKeyFromEnv(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "claude-key")
+	assert.Equal(t, "claude-key", APIKeyFromEnv("claude"))
+	assert.Empty(t, APIKeyFromEnv("ollama"))
+}
@@ -1,144 +1,543 @@
 package main
 
 import (
-	"bytes"
+	"bufio"
+	"cmp"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/revrost/glimpse/config"
+	"github.com/revrost/glimpse/fix"
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/history"
+	"github.com/revrost/glimpse/review"
 	"github.com/revrost/glimpse/styles"
+	"github.com/revrost/glimpse/ui"
 )
 
+// Snapshots of the working tree and index taken before the last fix and
+// before undoing it
 const (
-	crushTimeout = 5 * time.Minute
+	fixSnapshotRef  = "refs/glimpse/fix/before"
+	undoSnapshotRef = "refs/glimpse/fix/undone"
 )
 
-// parseFixResponse parses the LLM response to extract fix decision and review
-func parseFixResponse(content string) (needFix bool, review string, err error) {
-	if strings.TrimSpace(content) == "" {
-		return false, "", fmt.Errorf("empty response")
+const fixUsage = `Usage: glimpse fix <command>
+
+Commands:
+  undo   Restore the working tree and index from before the last fix`
+
+// runFixCommand handles "glimpse fix ..." and returns the exit code
+func runFixCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, fixUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "undo":
+		return runFixUndo(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Unknown fix command %q", args[0])))
+		fmt.Fprintln(os.Stderr, fixUsage)
+		return 2
 	}
+}
 
-	lines := strings.Split(content, "\n")
+// runFixUndo restores the snapshot taken before the last fix. The current
+// state is saved first, so the undo can be reverted too.
+func runFixUndo(args []string) int {
+	flags := flag.NewFlagSet("fix undo", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
 
-	// Find first non-empty line for header detection
-	var headerLine int
-	var header string
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed != "" {
-			headerLine = i
-			header = trimmed
-			break
+	snapshot, err := git.LoadSnapshot(fixSnapshotRef)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle("No fix to undo"))
+		return 1
+	}
+	current, err := git.TakeSnapshot(undoSnapshotRef)
+	if err == nil {
+		err = git.RestoreSnapshot(snapshot)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+	if err := git.DeleteRef(fixSnapshotRef); err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle(err.Error()))
+	}
+
+	fmt.Println(styles.CreateSuccessStyle("Restored the working tree and index from before the fix"))
+	fmt.Println(styles.Muted.Render(fmt.Sprintf("The undone changes are saved in %s (%.12s)", undoSnapshotRef, current.Commit)))
+	return 0
+}
+
+// autoFixer fixes the findings of a review in fix mode: with a coding agent,
+// or natively with patches from the review model
+type autoFixer struct {
+	name    string
+	agent   *fix.Agent // nil for native fixes
+	native  fix.Native
+	yes     bool     // Apply and keep fixes without confirmation
+	verify  []string // Commands that must pass after a fix
+	retries int      // Further attempts while they fail
+	filter  review.Filter
+	history *history.Store // Where fix transcripts are saved with their review, if enabled
+
+	running atomic.Bool // A fix is in progress
+}
+
+// busy reports whether a fix is in progress. Its edits are reviewed when it
+// finishes, so watch mode does not review them on its own.
+func (f *autoFixer) busy() bool {
+	return f != nil && f.running.Load()
+}
+
+// newFixer returns the configured fixer in fix mode, or nil otherwise.
+// It fails when the agent cannot run, so fix mode fails before reviewing.
+func newFixer(cfg *config.Config, fixMode bool, yes bool) (*autoFixer, error) {
+	if !fixMode {
+		return nil, nil
+	}
+	store, err := openHistory(cfg)
+	if err != nil {
+		return nil, err
+	}
+	fixer := &autoFixer{
+		name:    cfg.Fix.Agent,
+		yes:     yes,
+		verify:  cfg.Fix.Verify,
+		retries: max(cfg.Fix.Retries, 0),
+		filter:  reviewFilter(cfg),
+		history: store,
+	}
+	if cfg.Fix.Agent == fix.NativeAgent {
+		redactor, err := newRedactor(cfg)
+		if err != nil {
+			return nil, err
 		}
+		fixer.native = fix.Native{Client: newLLMClient(cfg), Redactor: redactor}
+		return fixer, nil
+	}
+
+	agent, err := newFixAgent(cfg)
+	if err != nil {
+		return nil, err
 	}
+	if err := agent.Check(); err != nil {
+		return nil, err
+	}
+	fixer.name, fixer.agent = agent.Name, agent
+	return fixer, nil
+}
 
-	// Check for explicit header (case-insensitive)
-	headerUpper := strings.ToUpper(header)
-	if strings.HasPrefix(headerUpper, "NEED FIX: YES") {
-		needFix = true
-		// Review starts after header line
-		review = strings.Join(lines[headerLine+1:], "\n")
-	} else if strings.HasPrefix(headerUpper, "NEED FIX: NO") {
-		needFix = false
-		review = strings.Join(lines[headerLine+1:], "\n")
-	} else {
-		// Fallback: keyword detection
-		contentLower := strings.ToLower(content)
-		noIssuesKeywords := []string{"no issues", "looks good", "everything is fine", "no problems", "all good"}
-
-		// Check if any "no issues" keywords are present
-		hasNoIssues := false
-		for _, keyword := range noIssuesKeywords {
-			if strings.Contains(contentLower, keyword) {
-				hasNoIssues = true
-				break
+// newFixAgent resolves fix.agent to a built-in profile, overridden by
+// fix.agents where set, or to a custom agent from fix.agents
+func newFixAgent(cfg *config.Config) (*fix.Agent, error) {
+	name := cfg.Fix.Agent
+	if name == "" {
+		name = "crush"
+	}
+	agent, builtin := fix.Builtin[name]
+	custom, ok := cfg.Fix.Agents[name]
+	if !builtin && !ok {
+		return nil, fmt.Errorf("unknown fix agent %q (built-in: %s, or %s for patches from the review model; define others under fix.agents)",
+			name, strings.Join(fix.Names(), ", "), fix.NativeAgent)
+	}
+
+	agent.Name = name
+	if len(custom.Command) > 0 {
+		agent.Command, agent.Install = custom.Command, ""
+	}
+	if custom.Prompt != "" || !builtin {
+		delivery, err := fix.ParseDelivery(custom.Prompt)
+		if err != nil {
+			return nil, fmt.Errorf("fix agent %s: %w", name, err)
+		}
+		agent.Prompt = delivery
+	}
+	if custom.Timeout != "" {
+		timeout, err := time.ParseDuration(custom.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("fix agent %s: invalid timeout %q", name, custom.Timeout)
+		}
+		agent.Timeout = timeout
+	}
+	if custom.MaxPrompt < 0 {
+		return nil, fmt.Errorf("fix agent %s: invalid max_prompt %d", name, custom.MaxPrompt)
+	}
+	agent.MaxPrompt = cmp.Or(custom.MaxPrompt, fix.MaxArgvPrompt)
+	longPrompt, err := fix.ParseLongPrompt(custom.LongPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("fix agent %s: %w", name, err)
+	}
+	agent.LongPrompt = longPrompt
+	return &agent, nil
+}
+
+// fixFindings fixes the findings and runs the verify commands, feeding their
+// failures back for up to fix.retries more attempts. Agents work in a temporary
+// worktree, and their changes reach the checkout only once they are confirmed.
+// It then reviews the files again and prints what was fixed. Staged reviews are
+// patched in the index as well as the working tree. The transcript of the fix
+// is saved with the review reviewID in the history.
+func fixFindings(ctx context.Context, fixer *autoFixer, reviewer *review.Reviewer, req review.Request, reviewID string, findings []review.Finding, staged bool) {
+	if len(findings) == 0 {
+		fmt.Println(styles.CreateInfoStyle("No fixes needed."))
+		return
+	}
+	fixer.running.Store(true)
+	defer fixer.running.Store(false)
+
+	// Agents edit a copy of the working tree; native patches are confirmed one by
+	// one as they are applied, and can be undone
+	var worktree *git.Worktree
+	if fixer.agent != nil {
+		base, err := git.TakeSnapshot("")
+		if err == nil {
+			worktree, err = git.AddWorktree(base.Commit)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Fix skipped, no worktree for the agent: %v", err)))
+			return
+		}
+		defer worktree.Remove()
+	} else if _, err := git.TakeSnapshot(fixSnapshotRef); err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Fix skipped, the working tree cannot be saved: %v", err)))
+		return
+	}
+	dir := ""
+	if worktree != nil {
+		dir = worktree.Dir
+	}
+
+	var transcript fix.Transcript
+	defer fixer.record(reviewID, &transcript)
+
+	var checks []fix.Check
+	var feedback string
+	for attempt := 0; ; attempt++ {
+		if !fixer.attempt(ctx, dir, findings, feedback, staged, &transcript) {
+			if attempt == 0 {
+				if worktree != nil {
+					fmt.Println(styles.Muted.Render("The checkout was not changed."))
+				}
+				return
 			}
+			break
 		}
+		if len(fixer.verify) == 0 {
+			break
+		}
+		checks = fix.Verify(ctx, dir, fixer.verify)
+		if ctx.Err() != nil {
+			return
+		}
+		printChecks(checks)
+		if fix.Passed(checks) || attempt >= fixer.retries {
+			break
+		}
+		feedback = fix.Feedback(checks)
+		fmt.Fprintf(&transcript, "\n--- CHECKS FAILED, RETRYING ---\n%s\n", feedback)
+		fmt.Println(styles.CreateWarningStyle(fmt.Sprintf("Checks failed, retrying the fix (%d/%d)", attempt+1, fixer.retries)))
+	}
+	if worktree != nil && !fixer.land(ctx, worktree, staged) {
+		return
+	}
+	fmt.Println(styles.Muted.Render("Run 'glimpse fix undo' to restore the working tree and index from before the fix"))
 
-		needFix = !hasNoIssues
-		review = content // No header, use entire content as review
+	after, ok := reviewFix(ctx, reviewer, req, staged)
+	if ctx.Err() != nil {
+		return
+	}
+	fmt.Println(styles.CreateHeader("--- FIX SUMMARY ---"))
+	if ok {
+		changes := review.Compare(findings, fixer.filter.Apply(after))
+		printSection("Fixed", changes.Resolved)
+		printSection("Remaining", changes.Open)
+		printSection("Introduced", changes.New)
 	}
+	switch {
+	case len(fixer.verify) == 0:
+		fmt.Println(styles.Muted.Render("Checks: none configured (fix.verify)"))
+	case fix.Passed(checks):
+		fmt.Println(styles.CreateSuccessStyle("Checks: passing"))
+	default:
+		fmt.Println(styles.CreateErrorStyle("Checks: failing"))
+	}
+}
 
-	// Clean up review: remove leading/trailing whitespace
-	review = strings.TrimSpace(review)
+// attempt runs the fixer once, with the failed checks of the previous attempt
+// if any, and reports whether it changed anything. Agents run in dir. What the
+// fixer does is also written to transcript.
+func (f *autoFixer) attempt(ctx context.Context, dir string, findings []review.Finding, feedback string, staged bool, transcript io.Writer) bool {
+	if f.agent == nil {
+		native := f.native
+		native.Index = staged
+		return applyPatches(ctx, native, findings, feedback, f.yes, transcript) > 0
+	}
 
-	return needFix, review, nil
+	prompt := review.FormatFixPrompt(findings)
+	if feedback != "" {
+		prompt += "\nThe previous fix failed these checks. Fix them as well:\n\n" + feedback
+	}
+	agent := *f.agent
+	agent.Dir = dir
+	if err := runFixAgent(ctx, &agent, prompt, transcript); err != nil {
+		return false
+	}
+	fmt.Println(styles.CreateInfoStyle("Fix execution complete."))
+	return true
 }
 
-// reverseStrings reverses a slice of strings
-func reverseStrings(s []string) []string {
-	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
-		s[i], s[j] = s[j], s[i]
+// record saves the transcript of a fix with the review it fixed
+func (f *autoFixer) record(reviewID string, transcript *fix.Transcript) {
+	if f.history == nil || reviewID == "" {
+		return
+	}
+	err := f.history.Update(reviewID, func(entry *history.Entry) {
+		entry.Fix = &history.Fix{Agent: f.name, Transcript: transcript.String()}
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle(fmt.Sprintf("Could not save the fix transcript to history: %v", err)))
 	}
-	return s
 }
 
-// runCrushFix executes crush with the review and streams output
-func runCrushFix(review string) error {
-	// Check if crush is installed
-	_, err := exec.LookPath("crush")
+// land shows the changes the agent made in its worktree and applies them to the
+// checkout once confirmed, after saving the checkout so they can be undone.
+// Without a terminal to confirm in, only --yes applies them.
+func (f *autoFixer) land(ctx context.Context, worktree *git.Worktree, staged bool) bool {
+	diff, err := worktree.Diff()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(
-			"crush not found. Install with: go install github.com/charmbracelet/crush@latest",
-		))
-		return fmt.Errorf("crush binary not found")
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Cannot read the changes of %s: %v", f.name, err)))
+		return false
+	}
+	if diff == "" {
+		fmt.Println(styles.CreateInfoStyle("The fix changed nothing."))
+		return false
 	}
 
-	// Prepend simple instruction to the review
-	prompt := "Fix all critical reviews mentioned in above:\n\n" + review
+	fmt.Println(styles.CreateHeader(fmt.Sprintf("--- CHANGES BY %s ---", strings.ToUpper(f.name))))
+	printMarkdown("```diff\n" + diff + "```")
+	if !f.yes {
+		if !ui.IsTerminal(os.Stdin) {
+			fmt.Println(styles.Muted.Render("Changes not applied. Run with --yes to apply agent fixes without a terminal to confirm them"))
+			return false
+		}
+		answer, err := ask(ctx, bufio.NewReader(os.Stdin), "Apply these changes? [Y/n]: ")
+		if err != nil {
+			return false
+		}
+		if answer == "n" || answer == "no" {
+			fmt.Println(styles.CreateInfoStyle("Changes discarded."))
+			return false
+		}
+	}
 
-	// Truncate prompt if too long (avoid command line limits)
-	const maxPromptLength = 10000
-	if len(prompt) > maxPromptLength {
-		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle(
-			fmt.Sprintf("Review truncated from %d to %d characters", len(prompt), maxPromptLength),
-		))
-		prompt = prompt[:maxPromptLength]
+	if _, err := git.TakeSnapshot(fixSnapshotRef); err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Changes not applied, the working tree cannot be saved: %v", err)))
+		return false
+	}
+	index := staged && git.CheckPatch(diff, true) == nil
+	if staged && !index {
+		fmt.Println(styles.CreateWarningStyle("The staged files differ from the working tree, so the fix is not staged"))
+	}
+	if err := git.ApplyPatch(diff, index); err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Failed to apply the changes, the checkout changed while %s ran: %v", f.name, err)))
+		return false
+	}
+	fmt.Println(styles.CreateSuccessStyle("Changes applied."))
+	return true
+}
+
+// printChecks prints the outcome of each verify command, with the output of failures
+func printChecks(checks []fix.Check) {
+	for _, check := range checks {
+		if check.Err == nil {
+			fmt.Println(styles.CreateSuccessStyle("✓ " + check.Command))
+			continue
+		}
+		fmt.Println(styles.CreateErrorStyle(fmt.Sprintf("✗ %s (%v)", check.Command, check.Err)))
+		if output := strings.TrimSpace(check.Output); output != "" {
+			fmt.Println(output)
+		}
+	}
+}
+
+// reviewFix reviews the files of req again as they are after a fix, and
+// returns the findings. It returns false when the review failed.
+func reviewFix(ctx context.Context, reviewer *review.Reviewer, req review.Request, staged bool) ([]review.Finding, bool) {
+	files := make([]string, len(req.Diffs))
+	for i, d := range req.Diffs {
+		files[i] = d.FilePath
+	}
+
+	var diffs []git.Diff
+	var err error
+	switch {
+	case staged:
+		diffs, err = git.GetStagedDiff(files...)
+		req.StagedHash, _ = git.StagedHash()
+	case req.Base != "":
+		diffs, err = git.GetDiffFrom(req.Base, files...)
+	default:
+		diffs, err = git.GetDiff(files...)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Failed to diff the fixed files: %v", err)))
+		return nil, false
+	}
+	req.Diffs, req.Baseline, req.Stream = diffs, nil, false
+
+	fmt.Println(styles.Info.Render("Reviewing the fixed changes..."))
+	result, err := reviewer.Review(ctx, req)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		}
+		return nil, false
+	}
+	if !printReviewNotes(os.Stdout, result) {
+		return nil, false
 	}
+	return result.Findings, true
+}
 
-	fmt.Println(styles.CreateHeader("--- RUNNING CRUSH TO FIX ---"))
+// applyPatches asks the review model for patches and applies each one that
+// checks out, after confirmation unless yes is set. Applied patches are written
+// to transcript. It returns how many were applied.
+func applyPatches(ctx context.Context, native fix.Native, findings []review.Finding, feedback string, yes bool, transcript io.Writer) int {
+	fmt.Println(styles.CreateHeader("--- PROPOSING PATCHES ---"))
+	patches, err := native.Propose(ctx, findings, feedback)
+	if ctx.Err() != nil {
+		return 0
+	}
+	if err != nil {
+		if len(patches) == 0 {
+			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Failed to propose patches: %v", err)))
+			return 0
+		}
+		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle(err.Error()))
+	}
+	if len(patches) == 0 {
+		fmt.Println(styles.CreateInfoStyle("No patches proposed."))
+		return 0
+	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), crushTimeout)
-	defer cancel()
+	interactive := ui.IsTerminal(os.Stdin)
+	reader := bufio.NewReader(os.Stdin)
+	applied, skipped := 0, 0
+	for i, patch := range patches {
+		title := fmt.Sprintf("Patch %d/%d for %s", i+1, len(patches), patch.File)
+		if patch.Finding >= 1 && patch.Finding <= len(findings) {
+			title += ": " + findings[patch.Finding-1].Message
+		}
 
-	// Run crush command
-	cmd := exec.CommandContext(ctx, "crush", "run", prompt)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+		diff, err := native.Prepare(patch)
+		if err != nil {
+			fmt.Println(styles.CreateWarningStyle(fmt.Sprintf("%s\nRejected: %v", title, err)))
+			continue
+		}
+		fmt.Println(styles.CreateDiffHeader(title))
+		printMarkdown("```diff\n" + diff + "```")
 
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+		if !yes {
+			if !interactive {
+				skipped++
+				continue
+			}
+			answer, err := ask(ctx, reader, "Apply this patch? [y]es, [n]o, [a]ll, [q]uit: ")
+			if err != nil {
+				return 0
+			}
+			switch answer {
+			case "y", "yes":
+			case "a", "all":
+				yes = true
+			case "q", "quit":
+				fmt.Println(styles.Status.Render(fmt.Sprintf("Applied %d of %d patches", applied, len(patches))))
+				return applied
+			default:
+				continue
+			}
+		}
 
-	err = cmd.Run()
+		if err := native.Apply(diff); err != nil {
+			fmt.Println(styles.CreateWarningStyle(fmt.Sprintf("Rejected: %v", err)))
+			continue
+		}
+		fmt.Fprintf(transcript, "%s\n%s\n", title, diff)
+		applied++
+	}
 
-	// Stream output after command completes
-	fmt.Println(stdout.String())
-	if stderr.String() != "" {
-		fmt.Fprintln(os.Stderr, stderr.String())
+	fmt.Println(styles.Status.Render(fmt.Sprintf("Applied %d of %d patches", applied, len(patches))))
+	if skipped > 0 {
+		fmt.Println(styles.Muted.Render("Run with --yes to apply patches without a terminal to confirm them"))
 	}
+	return applied
+}
 
-	// Handle timeout
-	if ctx.Err() == context.DeadlineExceeded {
-		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle(
-			"crush execution timed out after 5 minutes",
-		))
-		return fmt.Errorf("crush timeout")
+// ask prompts for an answer on stdin. Cancelling ctx stops waiting for it, and
+// stdin closing without an answer is an error, so it never picks the default.
+func ask(ctx context.Context, reader *bufio.Reader, prompt string) (string, error) {
+	fmt.Print(prompt)
+	type reply struct {
+		line string
+		err  error
+	}
+	answer := make(chan reply, 1)
+	go func() {
+		line, err := reader.ReadString('\n')
+		if err != nil && line != "" {
+			err = nil // A last line without a newline
+		}
+		answer <- reply{strings.ToLower(strings.TrimSpace(line)), err}
+	}()
+	select {
+	case r := <-answer:
+		if r.err != nil {
+			fmt.Println()
+		}
+		return r.line, r.err
+	case <-ctx.Done():
+		fmt.Println()
+		return "", ctx.Err()
 	}
+}
 
-	// Handle execution error
-	if err != nil {
-		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(
-			fmt.Sprintf("crush execution failed: %v", err),
+// runFixAgent executes the fix agent with the review, streaming its output to
+// the terminal and to transcript. Cancelling ctx kills the agent.
+func runFixAgent(ctx context.Context, agent *fix.Agent, review string, transcript io.Writer) error {
+	// Prepend simple instruction to the review
+	prompt := "Fix all critical reviews mentioned in above:\n\n" + review
+
+	if agent.Truncates(prompt) {
+		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle(
+			fmt.Sprintf("Review truncated from %d to %d characters (fix.agents.%s.long_prompt is truncate)", len(prompt), agent.MaxPrompt, agent.Name),
 		))
-		return fmt.Errorf("crush failed: %w", err)
 	}
 
-	return nil
+	fmt.Println(styles.CreateHeader(fmt.Sprintf("--- RUNNING %s TO FIX ---", strings.ToUpper(agent.Name))))
+
+	err := agent.Run(ctx, prompt, io.MultiWriter(os.Stdout, transcript), io.MultiWriter(os.Stderr, transcript))
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		// Ctrl+C or superseded review
+		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle(fmt.Sprintf("%s execution cancelled", agent.Name)))
+	case errors.Is(err, fix.ErrTimeout):
+		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle(fmt.Sprintf("%s execution %v", agent.Name, err)))
+	case err != nil:
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("%s execution failed: %v", agent.Name, err)))
+	}
+	return err
 }
@@ -0,0 +1,263 @@
+// Package fix runs coding agents that fix review findings in the working tree.
+package fix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Delivery is how an agent receives its prompt
+type Delivery string
+
+// Prompt deliveries
+const (
+	DeliverArgv  Delivery = "argv"  // As a command line argument
+	DeliverStdin Delivery = "stdin" // On standard input
+	DeliverFile  Delivery = "file"  // In a temporary file whose path is passed
+
+	// DeliverTruncate is only a LongPrompt: the prompt is cut to MaxPrompt
+	DeliverTruncate Delivery = "truncate"
+)
+
+// Command placeholders, replaced in every argument
+const (
+	PromptPlaceholder = "{prompt}"
+	FilePlaceholder   = "{file}"
+)
+
+// DefaultTimeout bounds an agent run when its profile sets none
+const DefaultTimeout = 5 * time.Minute
+
+// MaxArgvPrompt is the default bound of prompts passed on the command line,
+// which the OS limits
+const MaxArgvPrompt = 10000
+
+// waitDelay bounds waiting for output after a command is killed, which
+// processes it spawned could otherwise hold open indefinitely
+const waitDelay = 5 * time.Second
+
+// ErrTimeout is returned when an agent runs longer than its timeout
+var ErrTimeout = errors.New("fix agent timed out")
+
+// Agent is an external program that edits the working tree to fix findings
+type Agent struct {
+	Name      string
+	Command   []string // Program and arguments, with optional placeholders
+	Prompt    Delivery
+	MaxPrompt int // Longest prompt passed as an argument (default MaxArgvPrompt)
+	// LongPrompt is how longer prompts are passed: in a file (the default),
+	// whose path replaces the prompt in an instruction, on stdin, or truncated
+	LongPrompt Delivery
+	Timeout    time.Duration
+	Install    string // How to install the program, shown when it is missing
+	Dir        string // Where the agent runs (default: the current directory)
+}
+
+// Builtin are the agent profiles that work without configuration
+var Builtin = map[string]Agent{
+	"crush": {
+		Name:    "crush",
+		Command: []string{"crush", "run", PromptPlaceholder},
+		Prompt:  DeliverArgv,
+		Install: "go install github.com/charmbracelet/crush@latest",
+	},
+	"aider": {
+		Name:    "aider",
+		Command: []string{"aider", "--yes-always", "--no-auto-commits", "--message-file", FilePlaceholder},
+		Prompt:  DeliverFile,
+		Install: "python -m pip install aider-install && aider-install",
+	},
+	"opencode": {
+		Name:    "opencode",
+		Command: []string{"opencode", "run", PromptPlaceholder},
+		Prompt:  DeliverArgv,
+		Install: "npm install -g opencode-ai",
+	},
+}
+
+// Names lists the built-in agents, sorted
+func Names() []string {
+	names := make([]string, 0, len(Builtin))
+	for name := range Builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseDelivery parses a prompt delivery name; empty is argv
+func ParseDelivery(s string) (Delivery, error) {
+	switch d := Delivery(strings.ToLower(strings.TrimSpace(s))); d {
+	case "":
+		return DeliverArgv, nil
+	case DeliverArgv, DeliverStdin, DeliverFile:
+		return d, nil
+	default:
+		return "", fmt.Errorf("invalid prompt delivery %q (expected argv, stdin or file)", s)
+	}
+}
+
+// ParseLongPrompt parses how prompts too long for the command line are passed;
+// empty is file
+func ParseLongPrompt(s string) (Delivery, error) {
+	switch d := Delivery(strings.ToLower(strings.TrimSpace(s))); d {
+	case "":
+		return DeliverFile, nil
+	case DeliverFile, DeliverStdin, DeliverTruncate:
+		return d, nil
+	default:
+		return "", fmt.Errorf("invalid long prompt delivery %q (expected file, stdin or truncate)", s)
+	}
+}
+
+// Check reports whether the agent can run, so fix mode fails at startup
+// rather than after a review
+func (a Agent) Check() error {
+	if len(a.Command) == 0 || a.Command[0] == "" {
+		return fmt.Errorf("fix agent %q has no command", a.Name)
+	}
+	if _, err := exec.LookPath(a.Command[0]); err != nil {
+		if a.Install != "" {
+			return fmt.Errorf("fix agent %s: %s not found. Install with: %s", a.Name, a.Command[0], a.Install)
+		}
+		return fmt.Errorf("fix agent %s: %s not found in PATH", a.Name, a.Command[0])
+	}
+	return nil
+}
+
+// Truncates reports whether a prompt is cut because it is too long for the
+// agent's command line
+func (a Agent) Truncates(prompt string) bool {
+	return a.overflows(prompt) && a.LongPrompt == DeliverTruncate
+}
+
+// overflows reports whether a prompt is too long for the agent's command line
+func (a Agent) overflows(prompt string) bool {
+	return (a.Prompt == DeliverArgv || a.Prompt == "") && len(prompt) > a.maxPrompt()
+}
+
+// maxPrompt is the longest prompt passed as an argument
+func (a Agent) maxPrompt() int {
+	if a.MaxPrompt > 0 {
+		return a.MaxPrompt
+	}
+	return MaxArgvPrompt
+}
+
+// Run runs the agent with the prompt. Cancelling ctx kills it.
+func (a Agent) Run(ctx context.Context, prompt string, stdout, stderr io.Writer) error {
+	if err := a.Check(); err != nil {
+		return err
+	}
+
+	timeout := a.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args, stdin, cleanup, err := a.args(prompt)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = a.Dir
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = waitDelay
+	killGroup(cmd)
+	err = cmd.Run()
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w after %s", ErrTimeout, timeout)
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case err != nil:
+		return fmt.Errorf("%s failed: %w", a.Name, err)
+	}
+	return nil
+}
+
+// args builds the command line and input for a prompt. cleanup removes the
+// prompt file, if any.
+func (a Agent) args(prompt string) (args []string, stdin io.Reader, cleanup func(), err error) {
+	cleanup = func() {}
+
+	delivery := a.Prompt
+	if a.overflows(prompt) {
+		switch a.LongPrompt {
+		case DeliverTruncate:
+			n := a.maxPrompt()
+			for n > 0 && !utf8.RuneStart(prompt[n]) {
+				n-- // Do not split a character
+			}
+			prompt = prompt[:n]
+		case DeliverStdin:
+			delivery = DeliverStdin
+		default:
+			delivery = DeliverFile
+		}
+	}
+
+	// Placeholder values; last is appended when the command has no placeholder
+	values := make(map[string]string)
+	var last string
+	switch delivery {
+	case DeliverStdin:
+		stdin = strings.NewReader(prompt)
+	case DeliverFile:
+		f, err := os.CreateTemp("", "glimpse-fix-*.md")
+		if err != nil {
+			return nil, nil, cleanup, fmt.Errorf("failed to write prompt file: %w", err)
+		}
+		cleanup = func() { os.Remove(f.Name()) }
+		_, err = f.WriteString(prompt)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			cleanup()
+			return nil, nil, func() {}, fmt.Errorf("failed to write prompt file: %w", err)
+		}
+		values[FilePlaceholder] = f.Name()
+		values[PromptPlaceholder] = "Read and follow the instructions in " + f.Name()
+		last = values[PromptPlaceholder]
+		if a.Prompt == DeliverFile {
+			last = f.Name()
+		}
+	default:
+		values[PromptPlaceholder] = prompt
+		last = prompt
+	}
+
+	replaced := false
+	for _, arg := range a.Command {
+		if delivery == DeliverStdin && arg == PromptPlaceholder {
+			continue // The prompt goes on stdin instead
+		}
+		for _, placeholder := range []string{PromptPlaceholder, FilePlaceholder} {
+			if value, ok := values[placeholder]; ok && strings.Contains(arg, placeholder) {
+				arg = strings.ReplaceAll(arg, placeholder, value)
+				replaced = true
+			}
+		}
+		args = append(args, arg)
+	}
+	if last != "" && !replaced {
+		args = append(args, last) // No placeholder: the prompt or file goes last
+	}
+	return args, stdin, cleanup, nil
+}
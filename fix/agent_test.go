@@ -0,0 +1,127 @@
+package fix
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentArgs(t *testing.T) {
+	args, stdin, cleanup, err := Builtin["crush"].args("fix it")
+	assert.NoError(t, err)
+	cleanup()
+	assert.Equal(t, []string{"crush", "run", "fix it"}, args)
+	assert.Nil(t, stdin)
+
+	// Without a placeholder the prompt goes last
+	args, _, cleanup, err = Agent{Command: []string{"agent", "--message"}}.args("fix it")
+	assert.NoError(t, err)
+	cleanup()
+	assert.Equal(t, []string{"agent", "--message", "fix it"}, args)
+
+	args, _, cleanup, err = Agent{Command: []string{"agent"}, LongPrompt: DeliverTruncate}.args(strings.Repeat("x", MaxArgvPrompt+1))
+	assert.NoError(t, err)
+	cleanup()
+	assert.Len(t, args[1], MaxArgvPrompt, "command line prompts are truncated")
+
+	args, _, cleanup, err = Agent{Command: []string{"agent"}, MaxPrompt: 3, LongPrompt: DeliverTruncate}.args("ééé")
+	assert.NoError(t, err)
+	cleanup()
+	assert.Equal(t, "é", args[1], "characters are not split")
+
+	// Long prompts go in a file by default, and the argument points to it
+	args, _, cleanup, err = Agent{Command: []string{"agent", "run", PromptPlaceholder}, MaxPrompt: 3}.args("fix it")
+	assert.NoError(t, err)
+	file, ok := strings.CutPrefix(args[2], "Read and follow the instructions in ")
+	assert.True(t, ok, args[2])
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "fix it", string(content))
+	cleanup()
+
+	args, stdin, cleanup, err = Agent{Command: []string{"agent", "run", PromptPlaceholder}, MaxPrompt: 3, LongPrompt: DeliverStdin}.args("fix it")
+	assert.NoError(t, err)
+	cleanup()
+	assert.Equal(t, []string{"agent", "run"}, args)
+	assert.NotNil(t, stdin)
+
+	args, stdin, cleanup, err = Agent{Command: []string{"agent", "-"}, Prompt: DeliverStdin}.args("fix it")
+	assert.NoError(t, err)
+	cleanup()
+	assert.Equal(t, []string{"agent", "-"}, args)
+	assert.NotNil(t, stdin)
+
+	args, _, cleanup, err = Builtin["aider"].args("fix it")
+	assert.NoError(t, err)
+	file = args[len(args)-1]
+	content, err = os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "fix it", string(content))
+	cleanup()
+	assert.NoFileExists(t, file, "the prompt file is removed")
+}
+
+func TestAgentRun(t *testing.T) {
+	agent := Agent{Name: "cat", Command: []string{"sh", "-c", "cat; echo oops >&2"}, Prompt: DeliverStdin}
+	var stdout, stderr bytes.Buffer
+
+	err := agent.Run(context.Background(), "fix it", &stdout, &stderr)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "fix it", stdout.String())
+	assert.Equal(t, "oops\n", stderr.String())
+}
+
+func TestAgentRunTimeout(t *testing.T) {
+	agent := Agent{Name: "sleep", Command: []string{"sleep", "5"}, Prompt: DeliverStdin, Timeout: 50 * time.Millisecond}
+
+	err := agent.Run(context.Background(), "", &bytes.Buffer{}, &bytes.Buffer{})
+
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestAgentRunTimeoutKillsChildren(t *testing.T) {
+	// The background sleep inherits the agent's output pipes
+	agent := Agent{Name: "sleep", Command: []string{"sh", "-c", "sleep 30 & sleep 30"}, Prompt: DeliverStdin, Timeout: 50 * time.Millisecond}
+	start := time.Now()
+
+	err := agent.Run(context.Background(), "", &bytes.Buffer{}, &bytes.Buffer{})
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestAgentCheck(t *testing.T) {
+	assert.NoError(t, Agent{Command: []string{"sh"}}.Check())
+	assert.ErrorContains(t, Agent{Name: "x", Command: []string{"glimpse-no-such-agent"}, Install: "brew install x"}.Check(), "Install with: brew install x")
+	assert.Error(t, Agent{Name: "empty"}.Check())
+}
+
+func TestTranscriptKeepsTheEnd(t *testing.T) {
+	var transcript Transcript
+	transcript.Write([]byte("start\n"))
+	assert.Equal(t, "start\n", transcript.String())
+
+	transcript.Write([]byte(strings.Repeat("x", MaxTranscript) + "end"))
+
+	out := transcript.String()
+	assert.True(t, strings.HasPrefix(out, "[earlier output truncated]\n"))
+	assert.True(t, strings.HasSuffix(out, "xend"))
+	assert.NotContains(t, out, "start")
+}
+
+func TestParseDelivery(t *testing.T) {
+	d, err := ParseDelivery("")
+	assert.NoError(t, err)
+	assert.Equal(t, DeliverArgv, d)
+	d, err = ParseDelivery("File")
+	assert.NoError(t, err)
+	assert.Equal(t, DeliverFile, d)
+	_, err = ParseDelivery("pipe")
+	assert.Error(t, err)
+}
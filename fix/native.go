@@ -0,0 +1,163 @@
+package fix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/llm"
+	"github.com/revrost/glimpse/redact"
+	"github.com/revrost/glimpse/review"
+)
+
+// NativeAgent is the fix.agent that needs no external program: the review
+// model proposes patches and git applies them
+const NativeAgent = "native"
+
+// patchPrompt tells the model how to shape its patches
+const patchPrompt = `You fix code review findings with minimal, focused patches.
+
+Respond ONLY with a JSON object, without prose or markdown fences, shaped like:
+{"patches":[{"finding":1,"file":"path/to/file.go","search":"exact lines to replace","replace":"their replacement","diff":""}]}
+
+Rules:
+- finding is the number of the finding the patch fixes
+- Prefer search/replace: copy search exactly from the file as shown, including indentation, with enough lines to match only once; leave diff empty
+- Alternatively set diff to a unified diff of the file (--- a/path, +++ b/path and @@ hunks) and leave search and replace empty
+- Only patch the files shown, and skip findings you cannot fix safely. Return {"patches":[]} when there is nothing to fix.`
+
+// Native fixes findings without an external agent: it asks the review model
+// for patches and applies them with git apply
+type Native struct {
+	Client   *llm.Client
+	Redactor *redact.Redactor // Masks file contents before sending (nil disables)
+	Index    bool             // Patch the staged version: check against and apply to the index too
+}
+
+// Propose asks the model for patches fixing the findings and, after a failed
+// attempt, the checks described by feedback. Patches of files it was not
+// shown are dropped and reported in the error alongside the others.
+func (n Native) Propose(ctx context.Context, findings []review.Finding, feedback string) ([]Patch, error) {
+	var b strings.Builder
+	b.WriteString("=== FINDINGS ===\n")
+	b.WriteString(review.FormatFixPrompt(findings))
+
+	shown := make(map[string]bool)
+	for _, finding := range findings {
+		if finding.File == "" || shown[finding.File] {
+			continue
+		}
+		content, err := n.read(finding.File)
+		if err != nil {
+			continue // Deleted or unreadable: nothing to patch
+		}
+		if n.Redactor != nil {
+			content, _ = n.Redactor.Redact(finding.File, content)
+		}
+		shown[finding.File] = true
+		fmt.Fprintf(&b, "=== FILE %s ===\n%s", finding.File, content)
+		if !strings.HasSuffix(content, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	if len(shown) == 0 {
+		return nil, fmt.Errorf("none of the files with findings can be read")
+	}
+	task := "Propose patches that fix the findings."
+	if feedback != "" {
+		b.WriteString("=== FAILED CHECKS (after the previous patches) ===\n")
+		b.WriteString(feedback)
+		task = "Propose patches that fix the findings and make the failed checks pass."
+	}
+
+	resp := <-n.Client.Generate(ctx, llm.GenerateRequest{
+		SystemPrompt: patchPrompt,
+		Context:      b.String(),
+		Task:         task,
+		JSONSchema:   patchSchema(),
+	})
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	proposed, err := ParsePatches(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	patches := make([]Patch, 0, len(proposed))
+	for _, patch := range proposed {
+		if !shown[patch.File] {
+			errs = append(errs, fmt.Errorf("patch of %q dropped: the file was not part of the review", patch.File))
+			continue
+		}
+		patches = append(patches, patch)
+	}
+	return patches, errors.Join(errs...)
+}
+
+// Prepare renders a patch as a git diff of the current file and checks that it
+// applies. The error says why the patch was rejected.
+func (n Native) Prepare(p Patch) (string, error) {
+	content, err := n.read(p.File)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", p.File, err)
+	}
+	diff, err := p.Unified(content)
+	if err != nil {
+		return "", err
+	}
+	if err := git.CheckPatch(diff, n.Index); err != nil {
+		return "", err
+	}
+	return diff, nil
+}
+
+// Apply applies a diff returned by Prepare
+func (n Native) Apply(diff string) error {
+	return git.ApplyPatch(diff, n.Index)
+}
+
+// read returns the content of a file as it will be patched. Paths are relative
+// to the top of the working tree, as in findings.
+func (n Native) read(path string) (string, error) {
+	if n.Index {
+		return git.IndexFile(path)
+	}
+	root, err := git.Root()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(root, path))
+	return string(data), err
+}
+
+// patchSchema is the JSON schema of the patches response
+func patchSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"patches": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"finding": map[string]interface{}{"type": "integer"},
+						"file":    map[string]interface{}{"type": "string"},
+						"diff":    map[string]interface{}{"type": "string"},
+						"search":  map[string]interface{}{"type": "string"},
+						"replace": map[string]interface{}{"type": "string"},
+					},
+					"required":             []string{"finding", "file", "diff", "search", "replace"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"patches"},
+		"additionalProperties": false,
+	}
+}
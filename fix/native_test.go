@@ -0,0 +1,74 @@
+package fix
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/revrost/glimpse/llm"
+	"github.com/revrost/glimpse/review"
+	"github.com/stretchr/testify/assert"
+)
+
+// initRepo creates a repository in a temporary working directory with a committed file
+func initRepo(t *testing.T, name, content string) {
+	t.Chdir(t.TempDir())
+	assert.NoError(t, os.WriteFile(name, []byte(content), 0644))
+	for _, args := range [][]string{{"init", "-q"}, {"add", name}, {"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-qm", "init"}} {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+}
+
+func TestNativeProposesAndApplies(t *testing.T) {
+	initRepo(t, "a.go", "package a\n\nvar x = 1\n")
+	fake := &llm.Fake{Content: `{"patches":[
+		{"finding":1,"file":"a.go","search":"var x = 1","replace":"var x = 2","diff":""},
+		{"finding":1,"file":"a.go","search":"var y = 1","replace":"var y = 2","diff":""},
+		{"finding":1,"file":"other.go","search":"x","replace":"y","diff":""}]}`}
+	native := Native{Client: llm.NewWithProvider(llm.Config{}, fake)}
+	findings := []review.Finding{{File: "a.go", StartLine: 3, Severity: review.SeverityHigh, Category: "bug", Message: "x is wrong"}}
+
+	patches, err := native.Propose(context.Background(), findings, "")
+
+	assert.ErrorContains(t, err, "other.go")
+	assert.Len(t, patches, 2)
+	assert.Contains(t, fake.Requests()[0].Context, "=== FILE a.go ===\npackage a\n")
+
+	diff, err := native.Prepare(patches[0])
+	assert.NoError(t, err)
+	assert.NoError(t, native.Apply(diff))
+	data, err := os.ReadFile("a.go")
+	assert.NoError(t, err)
+	assert.Equal(t, "package a\n\nvar x = 2\n", string(data))
+
+	_, err = native.Prepare(patches[1])
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestNativeFromSubdirectory(t *testing.T) {
+	initRepo(t, "a.go", "package a\n\nvar x = 1\n")
+	assert.NoError(t, os.Mkdir("sub", 0755))
+	t.Chdir("sub")
+
+	for _, native := range []Native{{}, {Index: true}} {
+		diff, err := native.Prepare(Patch{File: "a.go", Search: "var x = 1", Replace: "var x = 2"})
+		assert.NoError(t, err, "index %v", native.Index)
+		if native.Index {
+			assert.NoError(t, native.Apply(diff))
+		}
+	}
+	data, err := os.ReadFile("../a.go")
+	assert.NoError(t, err)
+	assert.Equal(t, "package a\n\nvar x = 2\n", string(data))
+}
+
+func TestNativeRejectsPatchesThatDoNotApply(t *testing.T) {
+	initRepo(t, "a.go", "package a\n\nvar x = 1\n")
+	native := Native{Index: true}
+
+	_, err := native.Prepare(Patch{File: "a.go", Diff: "@@ -3 +3 @@\n-var x = 3\n+var x = 2\n"})
+
+	assert.ErrorContains(t, err, "patch does not apply")
+}
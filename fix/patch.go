@@ -0,0 +1,156 @@
+package fix
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/review"
+)
+
+// contextLines are the unchanged lines around a search/replace hunk, as git diff shows
+const contextLines = 3
+
+// Patch is a change the model proposes for one finding: a unified diff of the
+// file, or an exact block of it to search for and replace
+type Patch struct {
+	Finding int    `json:"finding"` // 1-based number of the finding it fixes
+	File    string `json:"file"`
+	Diff    string `json:"diff"`
+	Search  string `json:"search"`
+	Replace string `json:"replace"`
+}
+
+// patchResponse is the JSON envelope the model is asked to return
+type patchResponse struct {
+	Patches []Patch `json:"patches"`
+}
+
+// ParsePatches decodes the patches in a model response
+func ParsePatches(content string) ([]Patch, error) {
+	document := review.ExtractJSON(content)
+	if document == "" {
+		return nil, fmt.Errorf("no JSON object found in response")
+	}
+	var resp patchResponse
+	if err := json.Unmarshal([]byte(document), &resp); err != nil {
+		return nil, fmt.Errorf("invalid patches JSON: %w", err)
+	}
+	return resp.Patches, nil
+}
+
+// Unified renders the patch as a git diff of its file, given the file's current content
+func (p Patch) Unified(content string) (string, error) {
+	if p.File == "" {
+		return "", fmt.Errorf("patch names no file")
+	}
+	if strings.TrimSpace(p.Diff) != "" {
+		return normalizeDiff(p.File, p.Diff)
+	}
+	return searchReplace(p.File, content, p.Search, p.Replace)
+}
+
+// normalizeDiff rebuilds a model's diff of file with git's headers. Diffs of
+// other files and malformed hunks are rejected.
+func normalizeDiff(file, diff string) (string, error) {
+	lines := strings.Split(strings.TrimSuffix(strings.ReplaceAll(diff, "\r\n", "\n"), "\n"), "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "+++ ") && i > 0 && strings.HasPrefix(lines[i-1], "--- ") {
+			if start >= 0 {
+				return "", fmt.Errorf("diff changes more than one file")
+			}
+			path, _, _ := strings.Cut(strings.TrimPrefix(line, "+++ "), "\t")
+			if path = strings.TrimPrefix(path, "b/"); path != file {
+				return "", fmt.Errorf("diff changes %s instead of %s", path, file)
+			}
+		}
+		if start < 0 && strings.HasPrefix(line, "@@") {
+			start = i
+		}
+	}
+	if start < 0 {
+		return "", fmt.Errorf("diff has no hunks")
+	}
+
+	patch := header(file) + strings.Join(lines[start:], "\n") + "\n"
+	if _, err := git.ParseUnified(patch); err != nil {
+		return "", fmt.Errorf("malformed diff: %w", err)
+	}
+	return patch, nil
+}
+
+// searchReplace renders the replacement of the only occurrence of search in
+// content as a git diff of file
+func searchReplace(file, content, search, replace string) (string, error) {
+	if search == "" {
+		return "", fmt.Errorf("patch has neither a diff nor a search block")
+	}
+	switch n := strings.Count(content, search); {
+	case n == 0:
+		return "", fmt.Errorf("search block not found in %s", file)
+	case n > 1:
+		return "", fmt.Errorf("search block matches %d places in %s", n, file)
+	}
+	updated := strings.Replace(content, search, replace, 1)
+	if updated == content {
+		return "", fmt.Errorf("patch does not change %s", file)
+	}
+
+	// Lines keep their newline, so a missing one at the end of the file shows
+	before, after := splitLines(content), splitLines(updated)
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	start := max(prefix-contextLines, 0)
+	end := len(before) - suffix + min(suffix, contextLines)
+	hunk := git.Hunk{
+		OldStart: start + 1,
+		OldLines: end - start,
+		NewStart: start + 1,
+		NewLines: len(after) - len(before) + end - start,
+	}
+	add := func(kind git.LineKind, lines []string) {
+		for _, line := range lines {
+			hunk.Lines = append(hunk.Lines, git.Line{Kind: kind, Content: strings.TrimSuffix(line, "\n")})
+			if !strings.HasSuffix(line, "\n") {
+				hunk.Lines = append(hunk.Lines, git.Line{Kind: git.LineNoNewline, Content: " No newline at end of file"})
+			}
+		}
+	}
+	add(git.LineContext, before[start:prefix])
+	add(git.LineDeleted, before[prefix:len(before)-suffix])
+	add(git.LineAdded, after[prefix:len(after)-suffix])
+	add(git.LineContext, before[len(before)-suffix:end])
+
+	// Empty ranges start at the line before, as git writes them
+	if hunk.OldLines == 0 {
+		hunk.OldStart--
+	}
+	if hunk.NewLines == 0 {
+		hunk.NewStart--
+	}
+	return header(file) + hunk.String(), nil
+}
+
+// header is the git diff header of a modified file
+func header(file string) string {
+	return fmt.Sprintf("diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", file, file, file, file)
+}
+
+// splitLines splits content after every newline
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,62 @@
+package fix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePatches(t *testing.T) {
+	patches, err := ParsePatches("```json\n{\"patches\":[{\"finding\":2,\"file\":\"a.go\",\"search\":\"x\",\"replace\":\"y\",\"diff\":\"\"}]}\n```")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Patch{{Finding: 2, File: "a.go", Search: "x", Replace: "y"}}, patches)
+
+	_, err = ParsePatches("no patches")
+	assert.Error(t, err)
+}
+
+func TestSearchReplace(t *testing.T) {
+	content := "package a\n\nfunc A() {\n\tx := 1\n\t_ = x\n}\n\nfunc B() {}\n\nfunc C() {}\n"
+	patch := Patch{File: "a.go", Search: "x := 1", Replace: "x := 2\n\ty := 3"}
+
+	diff, err := patch.Unified(content)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n"+
+		"@@ -1,7 +1,8 @@\n package a\n \n func A() {\n-\tx := 1\n+\tx := 2\n+\ty := 3\n \t_ = x\n }\n \n", diff)
+}
+
+func TestSearchReplaceWithoutFinalNewline(t *testing.T) {
+	diff, err := Patch{File: "a.txt", Search: "two", Replace: "2"}.Unified("one\ntwo")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n"+
+		"@@ -1,2 +1,2 @@\n one\n-two\n\\ No newline at end of file\n+2\n\\ No newline at end of file\n", diff)
+}
+
+func TestPatchRejections(t *testing.T) {
+	cases := map[string]Patch{
+		"not found":           {File: "a.go", Search: "missing"},
+		"matches 2 places":    {File: "a.go", Search: "x"},
+		"does not change":     {File: "a.go", Search: "x = 1", Replace: "x = 1"},
+		"neither a diff":      {File: "a.go"},
+		"instead of a.go":     {File: "a.go", Diff: "--- a/b.go\n+++ b/b.go\n@@ -1 +1 @@\n-x\n+y\n"},
+		"more than one file":  {File: "a.go", Diff: "--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-x\n+y\n--- a/a.go\n+++ b/a.go\n@@ -2 +2 @@\n-x\n+y\n"},
+		"no hunks":            {File: "a.go", Diff: "replace x with y"},
+		"malformed diff":      {File: "a.go", Diff: "@@ -1 +1 @@\n-x\n*y\n"},
+		"patch names no file": {Search: "x"},
+	}
+	for reason, patch := range cases {
+		_, err := patch.Unified("x = 1\nx = 2\n")
+		assert.ErrorContains(t, err, reason)
+	}
+}
+
+func TestNormalizeDiff(t *testing.T) {
+	// Bare hunks get git's headers
+	diff, err := Patch{File: "a.go", Diff: "@@ -1 +1 @@\n-x\n+y"}.Unified("")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-x\n+y\n", diff)
+}
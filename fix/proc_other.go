@@ -0,0 +1,9 @@
+//go:build !unix
+
+package fix
+
+import "os/exec"
+
+// killGroup is a no-op where process groups are not available; only the
+// process itself is killed
+func killGroup(cmd *exec.Cmd) {}
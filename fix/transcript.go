@@ -0,0 +1,46 @@
+package fix
+
+import (
+	"sync"
+	"unicode/utf8"
+)
+
+// MaxTranscript bounds a transcript; only its end is kept beyond this
+const MaxTranscript = 256 << 10
+
+// Transcript captures what a fix printed while it streams to the terminal.
+// It is safe for concurrent writes, e.g. an agent's stdout and stderr.
+type Transcript struct {
+	mu        sync.Mutex
+	buf       []byte
+	truncated bool
+}
+
+// Write appends p. Past MaxTranscript the start is dropped, down to half the
+// limit at once so that a chatty agent does not copy the tail on every write.
+func (t *Transcript) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > MaxTranscript {
+		start := len(t.buf) - MaxTranscript/2
+		for start < len(t.buf) && !utf8.RuneStart(t.buf[start]) {
+			start++ // Do not split a character
+		}
+		t.buf = append(t.buf[:0], t.buf[start:]...)
+		t.truncated = true
+	}
+	return len(p), nil
+}
+
+// String returns the transcript, noting when its start was dropped
+func (t *Transcript) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.truncated {
+		return "[earlier output truncated]\n" + string(t.buf)
+	}
+	return string(t.buf)
+}
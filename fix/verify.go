@@ -0,0 +1,80 @@
+package fix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// maxFeedback bounds the output of a failed check sent back to the fixer
+const maxFeedback = 4000
+
+// Check is the outcome of one verify command
+type Check struct {
+	Command string
+	Output  string // Combined stdout and stderr
+	Err     error
+}
+
+// Verify runs the commands with sh -c in dir (the current directory when
+// empty) in order, each bounded by DefaultTimeout, and stops at the first failure
+func Verify(ctx context.Context, dir string, commands []string) []Check {
+	checks := make([]Check, 0, len(commands))
+	for _, command := range commands {
+		check := runCheck(ctx, dir, command)
+		checks = append(checks, check)
+		if check.Err != nil {
+			break
+		}
+	}
+	return checks
+}
+
+// runCheck runs one verify command
+func runCheck(ctx context.Context, dir, command string) Check {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = waitDelay
+	killGroup(cmd)
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", DefaultTimeout)
+	}
+	return Check{Command: command, Output: out.String(), Err: err}
+}
+
+// Passed reports whether every check succeeded
+func Passed(checks []Check) bool {
+	for _, check := range checks {
+		if check.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Feedback describes the failed checks for the next fix attempt. Long outputs
+// keep their end, where compilers and test runners summarize.
+func Feedback(checks []Check) string {
+	var b strings.Builder
+	for _, check := range checks {
+		if check.Err == nil {
+			continue
+		}
+		output := strings.TrimSpace(check.Output)
+		if len(output) > maxFeedback {
+			output = "..." + output[len(output)-maxFeedback:]
+		}
+		fmt.Fprintf(&b, "$ %s (%v)\n%s\n", check.Command, check.Err, output)
+	}
+	return b.String()
+}
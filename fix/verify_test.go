@@ -0,0 +1,40 @@
+package fix
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyStopsAtFirstFailure(t *testing.T) {
+	checks := Verify(context.Background(), "", []string{"echo ok", "echo broken >&2; exit 1", "echo never"})
+
+	assert.Len(t, checks, 2)
+	assert.NoError(t, checks[0].Err)
+	assert.Equal(t, "ok\n", checks[0].Output)
+	assert.Error(t, checks[1].Err)
+	assert.False(t, Passed(checks))
+	assert.True(t, Passed(checks[:1]))
+	assert.Equal(t, "$ echo broken >&2; exit 1 (exit status 1)\nbroken\n", Feedback(checks))
+}
+
+func TestVerifyRunsInDir(t *testing.T) {
+	dir := t.TempDir()
+
+	checks := Verify(context.Background(), dir, []string{"pwd"})
+
+	assert.True(t, Passed(checks))
+	assert.Contains(t, checks[0].Output, filepath.Base(dir))
+}
+
+func TestFeedbackKeepsTheEnd(t *testing.T) {
+	checks := []Check{{Command: "go test", Output: strings.Repeat("x", maxFeedback) + "FAIL", Err: assert.AnError}}
+
+	feedback := Feedback(checks)
+
+	assert.True(t, strings.HasSuffix(feedback, "xFAIL\n"))
+	assert.Less(t, len(feedback), maxFeedback+100)
+}
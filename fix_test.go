@@ -1,131 +1,157 @@
 package main
 
 import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
-
+	"time"
+
+	"github.com/revrost/glimpse/config"
+	"github.com/revrost/glimpse/fix"
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/history"
+	"github.com/revrost/glimpse/llm"
+	"github.com/revrost/glimpse/review"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestParseFixResponse_Yes(t *testing.T) {
-	input := `NEED FIX: YES
-
-Found critical bug in payment handler.
-Fix: Add error handling to the payment processing function.`
-
-	needFix, review, err := parseFixResponse(input)
-
+func TestNewFixAgent(t *testing.T) {
+	cfg := &config.Config{}
+	agent, err := newFixAgent(cfg)
 	assert.NoError(t, err)
-	assert.True(t, needFix)
-	assert.Contains(t, review, "Found critical bug")
-}
-
-func TestParseFixResponse_No(t *testing.T) {
-	input := `NEED FIX: NO
-
-Code looks good. No issues found.`
-
-	needFix, review, err := parseFixResponse(input)
+	assert.Equal(t, fix.Builtin["crush"].Command, agent.Command, "crush is the default")
 
+	cfg.Fix = config.FixConfig{Agent: "aider", Agents: map[string]config.AgentConfig{"aider": {Timeout: "10m"}}}
+	agent, err = newFixAgent(cfg)
 	assert.NoError(t, err)
-	assert.False(t, needFix)
-	assert.Contains(t, review, "Code looks good")
-}
-
-func TestParseFixResponse_MissingHeader_NoIssues(t *testing.T) {
-	input := `The code review is complete.
-No issues found. Everything looks good.`
+	assert.Equal(t, fix.DeliverFile, agent.Prompt, "overrides keep the built-in delivery")
+	assert.Equal(t, 10*time.Minute, agent.Timeout)
 
-	needFix, review, err := parseFixResponse(input)
+	cfg.Fix = config.FixConfig{Agent: "mine", Agents: map[string]config.AgentConfig{"mine": {Command: []string{"mine", "-"}, Prompt: "stdin"}}}
+	agent, err = newFixAgent(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, fix.Agent{Name: "mine", Command: []string{"mine", "-"}, Prompt: fix.DeliverStdin, MaxPrompt: fix.MaxArgvPrompt, LongPrompt: fix.DeliverFile}, *agent)
 
+	cfg.Fix = config.FixConfig{Agent: "crush", Agents: map[string]config.AgentConfig{"crush": {MaxPrompt: 500, LongPrompt: "truncate"}}}
+	agent, err = newFixAgent(cfg)
 	assert.NoError(t, err)
-	assert.False(t, needFix) // Should detect "no issues" keywords
-	assert.Contains(t, review, "The code review")
-}
+	assert.Equal(t, 500, agent.MaxPrompt)
+	assert.Equal(t, fix.DeliverTruncate, agent.LongPrompt)
 
-func TestParseFixResponse_MissingHeader_HasIssues(t *testing.T) {
-	input := `Found memory leak in cache handler.
-Potential nil pointer dereference on line 45.`
+	cfg.Fix = config.FixConfig{Agent: "codex"}
+	_, err = newFixAgent(cfg)
+	assert.ErrorContains(t, err, "aider, crush, opencode")
 
-	needFix, _, err := parseFixResponse(input)
+	cfg.Fix = config.FixConfig{Agent: "crush", Agents: map[string]config.AgentConfig{"crush": {Timeout: "soon"}}}
+	_, err = newFixAgent(cfg)
+	assert.ErrorContains(t, err, "invalid timeout")
 
-	assert.NoError(t, err)
-	assert.True(t, needFix) // Should detect issues present
+	cfg.Fix = config.FixConfig{Agent: "crush", Agents: map[string]config.AgentConfig{"crush": {LongPrompt: "split"}}}
+	_, err = newFixAgent(cfg)
+	assert.ErrorContains(t, err, "invalid long prompt delivery")
 }
 
-func TestParseFixResponse_Empty(t *testing.T) {
-	needFix, review, err := parseFixResponse("")
+func TestNewFixerNative(t *testing.T) {
+	fixer, err := newFixer(&config.Config{Fix: config.FixConfig{Agent: fix.NativeAgent}}, true, true)
+	assert.NoError(t, err)
+	assert.Equal(t, fix.NativeAgent, fixer.name)
+	assert.Nil(t, fixer.agent, "native fixes need no external program")
+	assert.True(t, fixer.yes)
 
-	assert.Error(t, err)
-	assert.False(t, needFix)
-	assert.Empty(t, review)
+	fixer, err = newFixer(&config.Config{}, false, true)
+	assert.NoError(t, err)
+	assert.Nil(t, fixer)
 }
 
-func TestParseFixResponse_MultipleFixes(t *testing.T) {
-	input := `NEED FIX: YES
-
-Issue 1: Missing null check
-Issue 2: Unclosed file handle
-
-Fix: Add null check before dereferencing pointer and ensure file is closed with defer.`
+func TestFixFindingsRetriesAndUndo(t *testing.T) {
+	initFixRepo(t)
+	promptFile := filepath.Join(t.TempDir(), "prompt.txt")
+
+	// The first attempt changes nothing, so the check fails once
+	script := "cat > " + promptFile + "; echo fixing a.go; if [ -f tried ]; then echo good > a.go; else touch tried; fi"
+	fixer := &autoFixer{
+		name:    "sh",
+		agent:   &fix.Agent{Name: "sh", Command: []string{"sh", "-c", script}, Prompt: fix.DeliverStdin},
+		yes:     true,
+		verify:  []string{"grep -q good a.go"},
+		retries: 1,
+		history: history.Open(t.TempDir(), 0),
+	}
+	entry, err := fixer.history.Append(history.Entry{Title: "GIT CHANGE REVIEW"})
+	assert.NoError(t, err)
+	fake := &llm.Fake{Content: `{"findings":[]}`}
+	reviewer := &review.Reviewer{Client: llm.NewWithProvider(llm.Config{}, fake)}
+	req := review.Request{Title: "GIT CHANGE REVIEW", Diffs: []git.Diff{{FilePath: "a.go"}}}
+	findings := []review.Finding{{File: "a.go", Severity: review.SeverityHigh, Category: "bug", Message: "bad"}}
 
-	needFix, _, err := parseFixResponse(input)
+	fixFindings(context.Background(), fixer, reviewer, req, entry.ID, findings, false)
 
+	prompt, err := os.ReadFile(promptFile)
 	assert.NoError(t, err)
-	assert.True(t, needFix)
-}
+	assert.Contains(t, string(prompt), "failed these checks")
+	assert.Contains(t, string(prompt), "$ grep -q good a.go")
+	assert.False(t, fixer.busy())
 
-func TestParseFixResponse_WhitespaceInHeader(t *testing.T) {
-	input := `  NEED FIX: YES
+	// The agent's output of both attempts is saved with the review
+	entry, err = fixer.history.Get(entry.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry.Fix) {
+		assert.Equal(t, "sh", entry.Fix.Agent)
+		assert.Equal(t, 2, strings.Count(entry.Fix.Transcript, "fixing a.go"))
+		assert.Contains(t, entry.Fix.Transcript, "CHECKS FAILED")
+	}
 
-Review text here.`
+	requests := fake.Requests()
+	assert.Len(t, requests, 1, "the fixed file is reviewed again")
+	assert.Contains(t, requests[0].Context, "+good")
 
-	needFix, review, err := parseFixResponse(input)
+	assert.FileExists(t, "tried", "the agent's changes are applied to the checkout")
 
+	// The fix can be undone, once
+	assert.Equal(t, 0, runFixUndo(nil))
+	data, err := os.ReadFile("a.go")
 	assert.NoError(t, err)
-	assert.True(t, needFix)
-	assert.Contains(t, review, "Review text here")
+	assert.Equal(t, "bad\n", string(data))
+	assert.NoFileExists(t, "tried", "files created by the fix are removed")
+	assert.Equal(t, 1, runFixUndo(nil))
 }
 
-func TestParseFixResponse_CaseInsensitiveHeader(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected bool
-	}{
-		{"lowercase yes", "need fix: yes\nReview", true},
-		{"mixed case yes", "Need Fix: YES\nReview", true},
-		{"uppercase no", "NEED FIX: NO\nReview", false},
-		{"lowercase no", "need fix: no\nReview", false},
+func TestFixFindingsPreviewsAgentChanges(t *testing.T) {
+	initFixRepo(t)
+	fixer := &autoFixer{
+		name:  "sh",
+		agent: &fix.Agent{Name: "sh", Command: []string{"sh", "-c", "echo good > a.go"}, Prompt: fix.DeliverStdin},
 	}
+	fake := &llm.Fake{Content: `{"findings":[]}`}
+	reviewer := &review.Reviewer{Client: llm.NewWithProvider(llm.Config{}, fake)}
+	findings := []review.Finding{{File: "a.go", Severity: review.SeverityHigh, Category: "bug", Message: "bad"}}
 
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			needFix, _, err := parseFixResponse(tc.input)
-			assert.NoError(t, err)
-			assert.Equal(t, tc.expected, needFix)
-		})
-	}
-}
-
-func TestParseFixResponse_HeaderNotFirstLine(t *testing.T) {
-	input := `
-
-NEED FIX: YES
-
-Review content here.`
+	// With nobody to confirm them, the changes are not applied
+	stdin, err := os.Open(os.DevNull)
+	assert.NoError(t, err)
+	defer stdin.Close()
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() { os.Stdin = oldStdin })
 
-	needFix, review, err := parseFixResponse(input)
+	fixFindings(context.Background(), fixer, reviewer, review.Request{}, "", findings, false)
 
+	data, err := os.ReadFile("a.go")
 	assert.NoError(t, err)
-	assert.True(t, needFix)
-	assert.Contains(t, review, "Review content here")
+	assert.Equal(t, "bad\n", string(data))
+	assert.Empty(t, fake.Requests())
+	assert.Equal(t, 1, runFixUndo(nil), "there is nothing to undo")
 }
 
-func TestReverseStrings(t *testing.T) {
-	input := []string{"a", "b", "c", "d"}
-	expected := []string{"d", "c", "b", "a"}
-
-	result := reverseStrings(input)
-
-	assert.Equal(t, expected, result)
+// initFixRepo creates a repository with a committed a.go, changed to "bad"
+func initFixRepo(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NoError(t, os.WriteFile("a.go", []byte("ok\n"), 0644))
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-qm", "init"}} {
+		assert.NoError(t, exec.Command("git", args...).Run())
+	}
+	assert.NoError(t, os.WriteFile("a.go", []byte("bad\n"), 0644))
 }
@@ -0,0 +1,35 @@
+package git
+
+// CheckPatch reports why a unified diff does not apply, without changing
+// anything. With index set it must apply to both the index and the working tree.
+func CheckPatch(patch string, index bool) error {
+	_, err := apply(patch, index, "--check")
+	return err
+}
+
+// ApplyPatch applies a unified diff to the working tree, and to the index when
+// index is set. Hunk line counts are recounted, since models often get them wrong.
+func ApplyPatch(patch string, index bool) error {
+	_, err := apply(patch, index)
+	return err
+}
+
+// IndexFile returns the content of a file as staged; path is relative to the
+// top of the working tree
+func IndexFile(path string) (string, error) {
+	return run("show", ":"+path)
+}
+
+// apply runs git apply with the patch on stdin at the top of the working tree,
+// since patch paths are relative to it
+func apply(patch string, index bool, args ...string) (string, error) {
+	root, err := Root()
+	if err != nil {
+		return "", err
+	}
+	args = append([]string{"apply", "--recount", "--whitespace=nowarn"}, args...)
+	if index {
+		args = append(args, "--index")
+	}
+	return runCmd(root, nil, patch, append(args, "-")...)
+}
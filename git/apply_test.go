@@ -0,0 +1,28 @@
+package git
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPatch(t *testing.T) {
+	initRepo(t)
+	commitFile(t, "a.go", "package a\n\nvar x = 1\n")
+
+	// Wrong hunk counts are recounted
+	patch := "--- a/a.go\n+++ b/a.go\n@@ -3,9 +3,9 @@\n-var x = 1\n+var x = 2\n"
+	assert.NoError(t, CheckPatch(patch, true))
+	assert.NoError(t, ApplyPatch(patch, true))
+
+	data, err := os.ReadFile("a.go")
+	assert.NoError(t, err)
+	assert.Equal(t, "package a\n\nvar x = 2\n", string(data))
+	staged, err := IndexFile("a.go")
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), staged)
+
+	err = CheckPatch(patch, false)
+	assert.ErrorContains(t, err, "patch does not apply")
+}
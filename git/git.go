@@ -4,107 +4,46 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
 // Diff represents a git diff for a specific file
 type Diff struct {
 	FilePath string
-	Content  string
+	Content  string // Raw patch text of the file
+
+	OldPath   string // Differs from FilePath for renames and copies
+	Status    FileStatus
+	Binary    bool
+	OldMode   string // Set when the mode changed or the file was deleted
+	NewMode   string // Set when the mode changed or the file was added
+	Additions int
+	Deletions int
+	Hunks     []Hunk
 }
 
-// GetDiff returns the git diff for the specified files or all changes if no files specified
+// GetDiff returns the diff of the working tree (staged and unstaged changes)
+// against HEAD, for the specified files or all changed files
 func GetDiff(files ...string) ([]Diff, error) {
-	diffs := make([]Diff, 0) // Initialize to empty slice instead of nil
-	
-	// If no files specified, get diff for all changed files
-	if len(files) == 0 {
-		cmd := exec.Command("git", "diff", "--name-only", "HEAD")
-		var out bytes.Buffer
-		cmd.Stdout = &out
-		
-		if err := cmd.Run(); err != nil {
-			return diffs, err
-		}
-		
-		// Parse output to get file list
-		// This is a simplified approach - in production would use proper parsing
-		// For now, we'll get a general diff
-		return getAllDiffs()
-	}
-	
-	// Get diff for specific files (including staged)
-	for _, file := range files {
-		// First check unstaged changes
-		cmd := exec.Command("git", "diff", "--unified=3", "HEAD", "--", file)
-		var out bytes.Buffer
-		cmd.Stdout = &out
-		
-		var content string
-		if err := cmd.Run(); err == nil {
-			content = out.String()
-		}
-		
-		// Then check staged changes
-		cmd2 := exec.Command("git", "diff", "--unified=3", "--cached", "HEAD", "--", file)
-		var stagedOut bytes.Buffer
-		cmd2.Stdout = &stagedOut
-		
-		if err := cmd2.Run(); err == nil {
-			if content != "" {
-				content += "\n" + stagedOut.String()
-			} else {
-				content = stagedOut.String()
-			}
-		}
-		
-		// Only add if there's actual content
-		if content != "" {
-			diffs = append(diffs, Diff{
-				FilePath: file,
-				Content:  content,
-			})
-		}
-	}
-	
-	return diffs, nil
+	return GetDiffFrom(headOrEmptyTree(), files...)
 }
 
-// getAllDiffs gets git diff for all changed files
-func getAllDiffs() ([]Diff, error) {
-	// Get diff for both staged and unstaged changes
-	cmd := exec.Command("git", "diff", "--unified=3", "HEAD")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	
-	if err := cmd.Run(); err != nil {
-		return nil, err
-	}
-	
-	// Also get staged changes if any
-	cmd2 := exec.Command("git", "diff", "--unified=3", "--cached", "HEAD")
-	var stagedOut bytes.Buffer
-	cmd2.Stdout = &stagedOut
-	
-	if err := cmd2.Run(); err == nil {
-		// Combine both diffs
-		stagedContent := stagedOut.String()
-		if stagedContent != "" {
-			return []Diff{
-				{
-					FilePath: "all_changes",
-					Content:  out.String() + "\n" + stagedContent,
-				},
-			}, nil
-		}
+// GetDiffFrom returns the diff of the working tree against a commit, for the
+// specified files or all changed files
+func GetDiffFrom(base string, files ...string) ([]Diff, error) {
+	return diffFiles(append([]string{base, "--"}, files...)...)
+}
+
+// headOrEmptyTree returns HEAD, or the empty tree before the first commit
+func headOrEmptyTree() string {
+	if _, err := revParse("HEAD"); err != nil {
+		return EmptyTree
 	}
-	return []Diff{
-		{
-			FilePath: "all_changes",
-			Content:  out.String(),
-		},
-	}, nil
+	return "HEAD"
 }
 
 // GetChangedFiles returns a list of all changed files (staged and unstaged)
@@ -163,99 +102,209 @@ func GetChangedFiles() ([]string, error) {
 // StagedState represents the current state of staged changes
 type StagedState struct {
 	StagedFiles []string
-	Hash        string // Hash of the staged state for change detection
+	Hash        string // Index content (see StagedHash) for change detection
 }
 
 // GetStagedState returns the current staged state with hash for change detection
 func GetStagedState() (*StagedState, error) {
-	// Get staged changes
-	cmd := exec.Command("git", "diff", "--name-only", "--cached", "HEAD")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	
-	if err := cmd.Run(); err != nil {
+	hash, err := StagedHash()
+	if err != nil {
 		return nil, err
 	}
-	
-	stagedFiles := make([]string, 0) // Initialize to empty slice instead of nil
-	if out.String() != "" {
-		files := strings.Split(out.String(), "\n")
-		// Filter out empty strings
-		var filtered []string
-		for _, file := range files {
-			if file != "" {
-				filtered = append(filtered, file)
-			}
-		}
-		stagedFiles = filtered
-	}
-	
-	// Create hash for change detection
-	hasher := sha256.New()
-	for _, file := range stagedFiles {
-		hasher.Write([]byte(file))
-		
-		// Use git status instead of diff content for more stable hashing
-		// This avoids the race condition where diff content might fluctuate
-		statusCmd := exec.Command("git", "status", "--porcelain", "--", file)
-		var statusOut bytes.Buffer
-		statusCmd.Stdout = &statusOut
-		if err := statusCmd.Run(); err == nil {
-			hasher.Write(statusOut.Bytes())
-		}
+	stagedFiles, err := StagedFiles()
+	if err != nil {
+		return nil, err
 	}
-	
-	hash := hex.EncodeToString(hasher.Sum(nil))
-	
+
 	return &StagedState{
 		StagedFiles: stagedFiles,
 		Hash:        hash,
 	}, nil
 }
 
-// GetStagedDiff returns only the staged diff for specified files
-func GetStagedDiff(files ...string) ([]Diff, error) {
-	diffs := make([]Diff, 0) // Initialize to empty slice instead of nil
-	
-	// If no files specified, get diff for all staged files
-	if len(files) == 0 {
-		cmd := exec.Command("git", "diff", "--name-only", "--cached", "HEAD")
-		var out bytes.Buffer
-		cmd.Stdout = &out
-		
-		if err := cmd.Run(); err != nil {
-			return diffs, err
+// StagedFiles returns the files whose staged content differs from HEAD
+func StagedFiles() ([]string, error) {
+	out, err := run("diff", "--name-only", "-z", "--cached", headOrEmptyTree(), "--")
+	if err != nil {
+		return nil, err
+	}
+	stagedFiles := make([]string, 0) // Initialize to empty slice instead of nil
+	for _, file := range strings.Split(out, "\x00") {
+		if file != "" {
+			stagedFiles = append(stagedFiles, file)
 		}
-		
-		if out.String() != "" {
-			files = strings.Split(out.String(), "\n")
-			// Filter out empty strings
-			var filtered []string
-			for _, file := range files {
-				if file != "" {
-					filtered = append(filtered, file)
-				}
-			}
-			files = filtered
+	}
+	return stagedFiles, nil
+}
+
+// StagedHash returns a hash of the index entries, which changes whenever any
+// staged content changes. Unlike write-tree it takes no index lock and writes
+// no objects, so polling it never gets in the way of the user's own git commands.
+func StagedHash() (string, error) {
+	entries, err := run("ls-files", "--stage", "-z")
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(entries))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// GetStagedDiff returns only the staged diff for specified files, or all
+// staged files when none are given
+func GetStagedDiff(files ...string) ([]Diff, error) {
+	return diffFiles(append([]string{"--cached", "--"}, files...)...)
+}
+
+// EmptyTree is the hash of git's empty tree, used as the base of root commits
+const EmptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// ZeroSHA is the all-zero object name git uses for missing refs
+const ZeroSHA = "0000000000000000000000000000000000000000"
+
+// GetRangeDiff returns the per-file diff between two commits
+func GetRangeDiff(base, head string) ([]Diff, error) {
+	return diffFiles(base, head, "--")
+}
+
+// MergeBase returns the best common ancestor of two commits
+func MergeBase(a, b string) (string, error) {
+	out, err := run("merge-base", a, b)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// PushBase returns the commit a new branch at head should be diffed against:
+// the parent of the oldest commit not on any remote, or EmptyTree for a root
+// commit. It returns "" when every commit is already on a remote.
+func PushBase(head string) (string, error) {
+	out, err := run("rev-list", "--reverse", head, "--not", "--remotes")
+	if err != nil {
+		return "", err
+	}
+	commits := splitLines(out)
+	if len(commits) == 0 {
+		return "", nil
+	}
+
+	return parentOf(commits[0]), nil
+}
+
+// HooksDir returns the hooks directory of the current repository, honouring core.hooksPath
+func HooksDir() (string, error) {
+	out, err := run("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Root returns the top-level directory of the current working tree
+func Root() (string, error) {
+	out, err := run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CommonDir returns the absolute git directory shared by all worktrees
+func CommonDir() (string, error) {
+	out, err := run("rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// RepoPaths are the files git rewrites when the index, HEAD or branches change,
+// and the repository's own ignore and attribute files. Index and Head belong
+// to the current worktree; the others live in the common dir.
+type RepoPaths struct {
+	Index          string
+	Head           string
+	Refs           string // Loose refs directory
+	PackedRefs     string
+	InfoExclude    string
+	InfoAttributes string
+}
+
+// ResolveRepoPaths returns the absolute paths of the current worktree's index,
+// HEAD, refs and info files, following .git files of linked worktrees and submodules
+func ResolveRepoPaths() (RepoPaths, error) {
+	out, err := run("rev-parse", "--path-format=absolute",
+		"--git-path", "index", "--git-path", "HEAD", "--git-common-dir",
+		"--git-path", "info/exclude", "--git-path", "info/attributes")
+	if err != nil {
+		return RepoPaths{}, err
+	}
+	lines := splitLines(out)
+	if len(lines) != 5 {
+		return RepoPaths{}, fmt.Errorf("unexpected rev-parse output %q", out)
+	}
+	return RepoPaths{
+		Index:          lines[0],
+		Head:           lines[1],
+		Refs:           filepath.Join(lines[2], "refs"),
+		PackedRefs:     filepath.Join(lines[2], "packed-refs"),
+		InfoExclude:    lines[3],
+		InfoAttributes: lines[4],
+	}, nil
+}
+
+// run executes git with args and returns stdout, including stderr in errors
+func run(args ...string) (string, error) {
+	return runWith(nil, "", args...)
+}
+
+// runWith is run with extra environment variables and input on stdin
+func runWith(env []string, input string, args ...string) (string, error) {
+	return runCmd("", env, input, args...)
+}
+
+// runIn is run in dir instead of the current directory
+func runIn(dir string, args ...string) (string, error) {
+	return runCmd(dir, nil, "", args...)
+}
+
+// runCmd runs git in dir, the current directory when empty, with extra
+// environment variables and input on stdin
+func runCmd(dir string, env []string, input string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
 		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
 	}
-	
-	// Get staged diff for specific files
-	for _, file := range files {
-		cmd := exec.Command("git", "diff", "--unified=3", "--cached", "HEAD", "--", file)
-		var out bytes.Buffer
-		cmd.Stdout = &out
-		
-		if err := cmd.Run(); err == nil {
-			content := out.String()
-			if content != "" {
-				diffs = append(diffs, Diff{
-					FilePath: file,
-					Content:  content,
-				})
-			}
+	return out.String(), nil
+}
+
+// splitLines splits command output into non-empty lines
+func splitLines(out string) []string {
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" {
+			lines = append(lines, line)
 		}
 	}
-	
-	return diffs, nil
-}
\ No newline at end of file
+	return lines
+}
+
+// CommitExists reports whether the object exists in the local repository
+func CommitExists(sha string) bool {
+	_, err := run("cat-file", "-e", sha+"^{commit}")
+	return err == nil
+}
@@ -0,0 +1,295 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FileStatus is how a file changed
+type FileStatus string
+
+// File statuses reported by git diff
+const (
+	StatusAdded      FileStatus = "added"
+	StatusModified   FileStatus = "modified"
+	StatusDeleted    FileStatus = "deleted"
+	StatusRenamed    FileStatus = "renamed"
+	StatusCopied     FileStatus = "copied"
+	StatusModeChange FileStatus = "mode-change" // Only the file mode changed
+)
+
+// LineKind marks a diff line as added, deleted or context
+type LineKind byte
+
+// Line kinds, using the diff prefix characters
+const (
+	LineContext   LineKind = ' '
+	LineAdded     LineKind = '+'
+	LineDeleted   LineKind = '-'
+	LineNoNewline LineKind = '\\' // "\ No newline at end of file"
+)
+
+// Line is a single line of a hunk. OldLine is 0 for added lines and NewLine is
+// 0 for deleted lines.
+type Line struct {
+	Kind    LineKind
+	Content string
+	OldLine int
+	NewLine int
+}
+
+// Hunk is a contiguous block of changes
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Section  string // Text after the closing @@, usually the enclosing function
+	Lines    []Line
+}
+
+// String renders the hunk back to unified diff format
+func (h Hunk) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ -%s +%s @@%s\n", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines), h.Section)
+	for _, line := range h.Lines {
+		b.WriteByte(byte(line.Kind))
+		b.WriteString(line.Content)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// hunkRange renders a hunk range, omitting the count when it is 1 as git does
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// hunkHeader matches "@@ -old[,count] +new[,count] @@ section"
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
+
+// diffArgs are the options of every diff the package parses
+var diffArgs = []string{"diff", "-z", "--numstat", "--patch", "--find-renames", "--unified=3", "--no-color", "--no-ext-diff"}
+
+// diffFiles runs a single git diff with the given revisions and pathspecs and parses it
+func diffFiles(args ...string) ([]Diff, error) {
+	out, err := run(append(append([]string{}, diffArgs...), args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePatch(out)
+}
+
+// numstat is one record of --numstat output
+type numstat struct {
+	additions, deletions int
+	binary               bool
+	oldPath, path        string
+}
+
+// ParsePatch parses the output of "git diff -z --numstat --patch" into one Diff per file
+func ParsePatch(out string) ([]Diff, error) {
+	if out == "" {
+		return make([]Diff, 0), nil
+	}
+
+	stats, patch, err := parseNumstat(out)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := ParseUnified(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sections follow numstat order; numstat has the exact, unquoted paths
+	if len(diffs) == len(stats) {
+		for i, stat := range stats {
+			d := &diffs[i]
+			d.FilePath, d.OldPath = stat.path, stat.path
+			if stat.oldPath != "" {
+				d.OldPath = stat.oldPath
+			}
+			d.Additions, d.Deletions = stat.additions, stat.deletions
+			d.Binary = d.Binary || stat.binary
+		}
+	}
+	return diffs, nil
+}
+
+// ParseUnified parses a plain "git diff" patch into one Diff per file.
+// Addition and deletion counts are taken from the hunks.
+func ParseUnified(patch string) ([]Diff, error) {
+	diffs := make([]Diff, 0)
+	for _, section := range splitSections(patch) {
+		d, err := parseFile(section)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// parseNumstat splits the NUL-separated numstat records from the patch that follows them
+func parseNumstat(out string) ([]numstat, string, error) {
+	var stats []numstat
+	rest := out
+	for {
+		record, remainder, ok := strings.Cut(rest, "\x00")
+		if !ok {
+			return nil, "", fmt.Errorf("unterminated numstat output")
+		}
+		rest = remainder
+		if record == "" {
+			return stats, rest, nil // An empty record ends the numstat block
+		}
+
+		fields := strings.SplitN(record, "\t", 3)
+		if len(fields) != 3 {
+			return nil, "", fmt.Errorf("invalid numstat record %q", record)
+		}
+		stat := numstat{path: fields[2]}
+		if fields[0] == "-" && fields[1] == "-" {
+			stat.binary = true
+		} else {
+			stat.additions, _ = strconv.Atoi(fields[0])
+			stat.deletions, _ = strconv.Atoi(fields[1])
+		}
+
+		if stat.path == "" {
+			// Renames and copies: the old and new paths follow as separate records
+			parts := strings.SplitN(rest, "\x00", 3)
+			if len(parts) != 3 {
+				return nil, "", fmt.Errorf("truncated numstat rename record")
+			}
+			stat.oldPath, stat.path, rest = parts[0], parts[1], parts[2]
+		}
+		stats = append(stats, stat)
+	}
+}
+
+// splitSections splits a patch into per-file sections starting with "diff --git"
+func splitSections(patch string) []string {
+	var sections []string
+	for _, section := range strings.SplitAfter(patch, "\n") {
+		if strings.HasPrefix(section, "diff --git ") || len(sections) == 0 {
+			sections = append(sections, section)
+			continue
+		}
+		sections[len(sections)-1] += section
+	}
+
+	var files []string
+	for _, section := range sections {
+		if strings.HasPrefix(section, "diff --git ") {
+			files = append(files, section)
+		}
+	}
+	return files
+}
+
+// parseFile parses one "diff --git" section
+func parseFile(section string) (Diff, error) {
+	d := Diff{Content: section, Status: StatusModified}
+	modeChanged := false
+
+	lines := strings.Split(strings.TrimSuffix(section, "\n"), "\n")
+	d.FilePath, d.OldPath = headerPaths(lines[0])
+
+	var hunk *Hunk
+	oldLine, newLine := 0, 0
+	for _, line := range lines[1:] {
+		if hunk == nil && !strings.HasPrefix(line, "@@ ") {
+			switch {
+			case strings.HasPrefix(line, "new file mode "):
+				d.Status = StatusAdded
+				d.NewMode = strings.TrimPrefix(line, "new file mode ")
+			case strings.HasPrefix(line, "deleted file mode "):
+				d.Status = StatusDeleted
+				d.OldMode = strings.TrimPrefix(line, "deleted file mode ")
+			case strings.HasPrefix(line, "old mode "):
+				d.OldMode = strings.TrimPrefix(line, "old mode ")
+				modeChanged = true
+			case strings.HasPrefix(line, "new mode "):
+				d.NewMode = strings.TrimPrefix(line, "new mode ")
+			case strings.HasPrefix(line, "rename from "):
+				d.Status = StatusRenamed
+			case strings.HasPrefix(line, "copy from "):
+				d.Status = StatusCopied
+			case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+				d.Binary = true
+			}
+			continue
+		}
+
+		if match := hunkHeader.FindStringSubmatch(line); match != nil {
+			d.Hunks = append(d.Hunks, Hunk{
+				OldStart: atoi(match[1], 0),
+				OldLines: atoi(match[2], 1),
+				NewStart: atoi(match[3], 0),
+				NewLines: atoi(match[4], 1),
+				Section:  match[5],
+			})
+			hunk = &d.Hunks[len(d.Hunks)-1]
+			oldLine, newLine = hunk.OldStart, hunk.NewStart
+			continue
+		}
+		if hunk == nil {
+			return Diff{}, fmt.Errorf("invalid hunk header %q", line)
+		}
+
+		kind, content := LineContext, ""
+		if line != "" { // Some tools strip the space of empty context lines
+			kind, content = LineKind(line[0]), line[1:]
+		}
+		parsed := Line{Kind: kind, Content: content}
+		switch kind {
+		case LineContext:
+			parsed.OldLine, parsed.NewLine = oldLine, newLine
+			oldLine++
+			newLine++
+		case LineAdded:
+			parsed.NewLine = newLine
+			newLine++
+			d.Additions++
+		case LineDeleted:
+			parsed.OldLine = oldLine
+			oldLine++
+			d.Deletions++
+		case LineNoNewline:
+		default:
+			return Diff{}, fmt.Errorf("invalid diff line %q", line)
+		}
+		hunk.Lines = append(hunk.Lines, parsed)
+	}
+
+	if modeChanged && d.Status == StatusModified && len(d.Hunks) == 0 && !d.Binary {
+		d.Status = StatusModeChange
+	}
+	return d, nil
+}
+
+// headerPaths extracts the new and old paths from "diff --git a/old b/new".
+// It is a fallback for when numstat paths are unavailable.
+func headerPaths(header string) (path, oldPath string) {
+	paths := strings.TrimPrefix(header, "diff --git ")
+	if old, path, ok := strings.Cut(paths, " b/"); ok {
+		return path, strings.TrimPrefix(old, "a/")
+	}
+	return paths, paths
+}
+
+// atoi parses a hunk range number, returning def when it is absent
+func atoi(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
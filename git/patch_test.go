@@ -0,0 +1,107 @@
+package git
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUnifiedLines(t *testing.T) {
+	patch := "diff --git a/a.txt b/a.txt\n" +
+		"index 1111111..2222222 100644\n" +
+		"--- a/a.txt\n" +
+		"+++ b/a.txt\n" +
+		"@@ -1,2 +1,3 @@ func main()\n" +
+		" keep\n" +
+		"-old\n" +
+		"+new\n" +
+		"+last\n" +
+		"\\ No newline at end of file\n"
+
+	diffs, err := ParseUnified(patch)
+
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 1)
+	d := diffs[0]
+	assert.Equal(t, "a.txt", d.FilePath)
+	assert.Equal(t, StatusModified, d.Status)
+	assert.Equal(t, 2, d.Additions)
+	assert.Equal(t, 1, d.Deletions)
+	assert.Len(t, d.Hunks, 1)
+
+	hunk := d.Hunks[0]
+	assert.Equal(t, " func main()", hunk.Section)
+	assert.Equal(t, []Line{
+		{Kind: LineContext, Content: "keep", OldLine: 1, NewLine: 1},
+		{Kind: LineDeleted, Content: "old", OldLine: 2},
+		{Kind: LineAdded, Content: "new", NewLine: 2},
+		{Kind: LineAdded, Content: "last", NewLine: 3},
+		{Kind: LineNoNewline, Content: " No newline at end of file"},
+	}, hunk.Lines)
+	assert.Equal(t, patch[len("diff --git a/a.txt b/a.txt\nindex 1111111..2222222 100644\n--- a/a.txt\n+++ b/a.txt\n"):], hunk.String())
+}
+
+func TestParsePatchNumstat(t *testing.T) {
+	out := "1\t0\tsp ace.txt\x00-\t-\tbin.dat\x000\t0\t\x00old.txt\x00new.txt\x00\x00" +
+		"diff --git a/sp ace.txt b/sp ace.txt\n" +
+		"new file mode 100644\n" +
+		"--- /dev/null\n" +
+		"+++ b/sp ace.txt\n" +
+		"@@ -0,0 +1 @@\n" +
+		"+hello\n" +
+		"diff --git a/bin.dat b/bin.dat\n" +
+		"index 1111111..2222222 100644\n" +
+		"Binary files a/bin.dat and b/bin.dat differ\n" +
+		"diff --git a/old.txt b/new.txt\n" +
+		"similarity index 100%\n" +
+		"rename from old.txt\n" +
+		"rename to new.txt\n"
+
+	diffs, err := ParsePatch(out)
+
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 3)
+	assert.Equal(t, "sp ace.txt", diffs[0].FilePath)
+	assert.Equal(t, StatusAdded, diffs[0].Status)
+	assert.Equal(t, "100644", diffs[0].NewMode)
+	assert.Equal(t, 1, diffs[0].Additions)
+	assert.True(t, diffs[1].Binary)
+	assert.Empty(t, diffs[1].Hunks)
+	assert.Equal(t, StatusRenamed, diffs[2].Status)
+	assert.Equal(t, "new.txt", diffs[2].FilePath)
+	assert.Equal(t, "old.txt", diffs[2].OldPath)
+
+	_, err = ParsePatch("1\t0\ta.txt")
+	assert.Error(t, err, "unterminated numstat")
+}
+
+func TestGetStagedDiffStatuses(t *testing.T) {
+	initRepo(t)
+	commitFile(t, "edit.txt", "one\n")
+	commitFile(t, "gone.txt", "bye\n")
+	commitFile(t, "move.txt", "a fairly long line so rename detection has content\n")
+	commitFile(t, "run.sh", "echo hi\n")
+
+	assert.NoError(t, os.WriteFile("edit.txt", []byte("one\ntwo\n"), 0644))
+	assert.NoError(t, os.WriteFile("new.txt", []byte("new\n"), 0644))
+	assert.NoError(t, os.Chmod("run.sh", 0755))
+	gitCmd(t, "rm", "-q", "gone.txt")
+	gitCmd(t, "mv", "move.txt", "moved.txt")
+	gitCmd(t, "add", "-A")
+
+	diffs, err := GetStagedDiff()
+	assert.NoError(t, err)
+
+	statuses := make(map[string]FileStatus)
+	for _, d := range diffs {
+		statuses[d.FilePath] = d.Status
+	}
+	assert.Equal(t, map[string]FileStatus{
+		"edit.txt":  StatusModified,
+		"gone.txt":  StatusDeleted,
+		"moved.txt": StatusRenamed,
+		"new.txt":   StatusAdded,
+		"run.sh":    StatusModeChange,
+	}, statuses)
+}
@@ -0,0 +1,121 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// initRepo creates a repository in a temp dir and makes it the working directory
+func initRepo(t *testing.T) string {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	gitCmd(t, "init", "-q")
+	gitCmd(t, "config", "user.email", "test@example.com")
+	gitCmd(t, "config", "user.name", "Test")
+	return dir
+}
+
+// gitCmd runs git in the working directory and returns its trimmed output
+func gitCmd(t *testing.T, args ...string) string {
+	out, err := exec.Command("git", args...).CombinedOutput()
+	assert.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// commitFile writes a file and commits it
+func commitFile(t *testing.T, name, content string) string {
+	assert.NoError(t, os.WriteFile(name, []byte(content), 0644))
+	gitCmd(t, "add", name)
+	gitCmd(t, "commit", "-q", "-m", "update "+name)
+	return gitCmd(t, "rev-parse", "HEAD")
+}
+
+func TestGetRangeDiff(t *testing.T) {
+	initRepo(t)
+	base := commitFile(t, "a.go", "package a\n")
+	commitFile(t, "a.go", "package a\n\nfunc A() {}\n")
+	head := commitFile(t, "b.go", "package b\n")
+
+	diffs, err := GetRangeDiff(base, head)
+
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 2)
+	assert.Equal(t, "a.go", diffs[0].FilePath)
+	assert.Contains(t, diffs[0].Content, "+func A() {}")
+	assert.Equal(t, "b.go", diffs[1].FilePath)
+}
+
+func TestMergeBase(t *testing.T) {
+	initRepo(t)
+	root := commitFile(t, "a.go", "package a\n")
+	gitCmd(t, "checkout", "-q", "-b", "feature")
+	feature := commitFile(t, "b.go", "package b\n")
+	gitCmd(t, "checkout", "-q", "-")
+	main := commitFile(t, "c.go", "package c\n")
+
+	base, err := MergeBase(main, feature)
+	assert.NoError(t, err)
+	assert.Equal(t, root, base)
+
+	_, err = MergeBase(main, ZeroSHA)
+	assert.Error(t, err)
+}
+
+func TestPushBase(t *testing.T) {
+	initRepo(t)
+	root := commitFile(t, "a.go", "package a\n")
+
+	// No remote: everything is unpushed, starting from the root commit
+	base, err := PushBase(root)
+	assert.NoError(t, err)
+	assert.Equal(t, EmptyTree, base)
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	gitCmd(t, "init", "-q", "--bare", remote)
+	gitCmd(t, "remote", "add", "origin", remote)
+	gitCmd(t, "push", "-q", "origin", "HEAD:refs/heads/main")
+
+	base, err = PushBase(root)
+	assert.NoError(t, err)
+	assert.Empty(t, base, "already pushed")
+
+	head := commitFile(t, "b.go", "package b\n")
+	base, err = PushBase(head)
+	assert.NoError(t, err)
+	assert.Equal(t, root, base)
+
+	assert.True(t, CommitExists(root))
+	assert.False(t, CommitExists(ZeroSHA))
+}
+
+func TestHooksDir(t *testing.T) {
+	initRepo(t)
+
+	dir, err := HooksDir()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(".git", "hooks"), dir)
+}
+
+func TestResolveRepoPathsInWorktree(t *testing.T) {
+	dir := initRepo(t)
+	commitFile(t, "a.go", "package a\n")
+	worktree := filepath.Join(t.TempDir(), "wt")
+	gitCmd(t, "worktree", "add", "-q", worktree)
+	t.Chdir(worktree)
+
+	paths, err := ResolveRepoPaths()
+
+	assert.NoError(t, err)
+	commonDir, _ := filepath.EvalSymlinks(filepath.Join(dir, ".git"))
+	assert.Equal(t, filepath.Join(commonDir, "refs"), paths.Refs)
+	assert.True(t, strings.HasPrefix(paths.Index, filepath.Join(commonDir, "worktrees")), "index is per worktree: %s", paths.Index)
+	assert.FileExists(t, paths.Index)
+	assert.FileExists(t, paths.Head)
+	assert.Equal(t, filepath.Join(commonDir, "info", "exclude"), paths.InfoExclude, "info files are shared by worktrees")
+	assert.Equal(t, filepath.Join(commonDir, "info", "attributes"), paths.InfoAttributes)
+}
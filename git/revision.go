@@ -0,0 +1,132 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Revision is a span of history to review: the changes from Base to Head
+type Revision struct {
+	Base string // Commit (or EmptyTree) the diff starts from
+	Head string
+}
+
+// Commit is a commit's identity and message
+type Commit struct {
+	SHA     string
+	Subject string
+	Body    string
+}
+
+// ResolveRange resolves a range as git diff does: "A...B" to the changes from
+// the merge base of A and B to B, and "A..B" to those from A to B. Either side
+// defaults to HEAD.
+func ResolveRange(spec string) (Revision, error) {
+	from, to, ok := strings.Cut(spec, "...")
+	direct := false
+	if !ok {
+		if from, to, ok = strings.Cut(spec, ".."); !ok {
+			return Revision{}, fmt.Errorf("invalid range %q (expected A...B or A..B)", spec)
+		}
+		direct = true
+	}
+	if from == "" {
+		from = "HEAD"
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+
+	head, err := revParse(to)
+	if err != nil {
+		return Revision{}, err
+	}
+	fromSHA, err := revParse(from)
+	if err != nil {
+		return Revision{}, err
+	}
+	if direct {
+		return Revision{Base: fromSHA, Head: head}, nil
+	}
+	base, err := MergeBase(fromSHA, head)
+	if err != nil {
+		return Revision{}, fmt.Errorf("no merge base between %s and %s: %w", from, to, err)
+	}
+	return Revision{Base: base, Head: head}, nil
+}
+
+// ResolveCommit resolves a single commit to its own changes
+func ResolveCommit(rev string) (Revision, error) {
+	head, err := revParse(rev)
+	if err != nil {
+		return Revision{}, err
+	}
+	return Revision{Base: parentOf(head), Head: head}, nil
+}
+
+// ResolveLast resolves the last n commits on HEAD. Asking for more commits
+// than exist reviews the whole history.
+func ResolveLast(n int) (Revision, error) {
+	if n < 1 {
+		return Revision{}, fmt.Errorf("--last must be at least 1, got %d", n)
+	}
+	head, err := revParse("HEAD")
+	if err != nil {
+		return Revision{}, err
+	}
+	base, err := revParse(fmt.Sprintf("HEAD~%d", n))
+	if err != nil {
+		base = EmptyTree
+	}
+	return Revision{Base: base, Head: head}, nil
+}
+
+// Diff returns the per-file diff of the revision
+func (r Revision) Diff() ([]Diff, error) {
+	return GetRangeDiff(r.Base, r.Head)
+}
+
+// Commits returns the commits between Base and Head, oldest first
+func (r Revision) Commits() ([]Commit, error) {
+	spec := r.Base + ".." + r.Head
+	if r.Base == EmptyTree {
+		spec = r.Head
+	}
+
+	out, err := run("log", "--reverse", "--format=%H%x00%s%x00%b%x1e", spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		commits = append(commits, Commit{
+			SHA:     fields[0],
+			Subject: fields[1],
+			Body:    strings.TrimSpace(fields[2]),
+		})
+	}
+	return commits, nil
+}
+
+// revParse resolves a revision to a commit SHA
+func revParse(rev string) (string, error) {
+	out, err := run("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %q", rev)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// parentOf returns the first parent of a commit, or EmptyTree for a root commit
+func parentOf(sha string) string {
+	parent, err := revParse(sha + "^")
+	if err != nil {
+		return EmptyTree
+	}
+	return parent
+}
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveRangeUsesMergeBase(t *testing.T) {
+	initRepo(t)
+	root := commitFile(t, "a.go", "package a\n")
+	gitCmd(t, "branch", "-M", "main")
+	gitCmd(t, "checkout", "-q", "-b", "feature")
+	commitFile(t, "b.go", "package b\n")
+	feature := commitFile(t, "b.go", "package b\n\nfunc B() {}\n")
+	gitCmd(t, "checkout", "-q", "main")
+	commitFile(t, "c.go", "package c\n") // main moves on after the branch point
+
+	revision, err := ResolveRange("main...feature")
+	assert.NoError(t, err)
+	assert.Equal(t, Revision{Base: root, Head: feature}, revision)
+
+	diffs, err := revision.Diff()
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 1, "main's own changes are not part of the branch")
+	assert.Equal(t, "b.go", diffs[0].FilePath)
+
+	commits, err := revision.Commits()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"update b.go", "update b.go"}, subjects(commits))
+	assert.Equal(t, feature, commits[1].SHA)
+
+	// Two dots compare the tips directly, as git diff does
+	revision, err = ResolveRange("main..feature")
+	assert.NoError(t, err)
+	assert.Equal(t, gitCmd(t, "rev-parse", "main"), revision.Base)
+	diffs, err = revision.Diff()
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 2, "main's own changes are reverted by the direct diff")
+
+	_, err = ResolveRange("main")
+	assert.ErrorContains(t, err, "invalid range")
+	_, err = ResolveRange("main...nope")
+	assert.ErrorContains(t, err, "unknown revision")
+}
+
+func TestResolveCommitAndLast(t *testing.T) {
+	initRepo(t)
+	root := commitFile(t, "a.go", "package a\n")
+	second := commitFile(t, "b.go", "package b\n")
+	gitCmd(t, "commit", "-q", "--allow-empty", "-m", "third", "-m", "with a body")
+
+	revision, err := ResolveCommit(second)
+	assert.NoError(t, err)
+	assert.Equal(t, root, revision.Base)
+
+	revision, err = ResolveCommit(root)
+	assert.NoError(t, err)
+	assert.Equal(t, EmptyTree, revision.Base)
+
+	revision, err = ResolveLast(2)
+	assert.NoError(t, err)
+	assert.Equal(t, root, revision.Base)
+	commits, err := revision.Commits()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"update b.go", "third"}, subjects(commits))
+	assert.Equal(t, "with a body", commits[1].Body)
+
+	// More commits than history covers everything
+	revision, err = ResolveLast(10)
+	assert.NoError(t, err)
+	assert.Equal(t, EmptyTree, revision.Base)
+	commits, err = revision.Commits()
+	assert.NoError(t, err)
+	assert.Len(t, commits, 3)
+
+	_, err = ResolveLast(0)
+	assert.Error(t, err)
+}
+
+func subjects(commits []Commit) []string {
+	var out []string
+	for _, commit := range commits {
+		out = append(out, commit.Subject)
+	}
+	return out
+}
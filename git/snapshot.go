@@ -0,0 +1,153 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// snapshotIdentity authors snapshot commits, which never leave the repository,
+// so they work without a configured user
+var snapshotIdentity = []string{
+	"GIT_AUTHOR_NAME=glimpse", "GIT_AUTHOR_EMAIL=glimpse@localhost",
+	"GIT_COMMITTER_NAME=glimpse", "GIT_COMMITTER_EMAIL=glimpse@localhost",
+}
+
+// Snapshot is a saved working tree and index
+type Snapshot struct {
+	Commit   string // Saved under the ref: its tree is WorkTree, its second parent's Index
+	WorkTree string // Tree of the working tree, untracked files included
+	Index    string // Tree of the index
+}
+
+// TakeSnapshot saves the working tree and the index in a commit under ref,
+// without changing either. Untracked files are included, ignored files are not.
+// An empty ref leaves the commit unreferenced.
+func TakeSnapshot(ref string) (Snapshot, error) {
+	index, err := run("write-tree")
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to save the index: %w", err)
+	}
+	s := Snapshot{Index: strings.TrimSpace(index)}
+	if s.WorkTree, err = currentTree(); err != nil {
+		return Snapshot{}, err
+	}
+
+	var parents []string
+	if head, err := revParse("HEAD"); err == nil {
+		parents = []string{"-p", head}
+	}
+	indexCommit, err := runWith(snapshotIdentity, "", append([]string{"commit-tree", s.Index, "-m", "glimpse: index"}, parents...)...)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to save the index: %w", err)
+	}
+	parents = append(parents, "-p", strings.TrimSpace(indexCommit))
+	commit, err := runWith(snapshotIdentity, "", append([]string{"commit-tree", s.WorkTree, "-m", "glimpse: snapshot"}, parents...)...)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to save the working tree: %w", err)
+	}
+	s.Commit = strings.TrimSpace(commit)
+
+	if ref == "" {
+		return s, nil
+	}
+	if _, err := run("update-ref", "-m", "glimpse snapshot", ref, s.Commit); err != nil {
+		return Snapshot{}, err
+	}
+	return s, nil
+}
+
+// LoadSnapshot reads the snapshot saved under ref
+func LoadSnapshot(ref string) (Snapshot, error) {
+	commit, err := revParse(ref)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("no snapshot at %s", ref)
+	}
+	s := Snapshot{Commit: commit}
+	s.WorkTree, err = run("rev-parse", "--verify", "--quiet", commit+"^{tree}")
+	if err == nil {
+		s.Index, err = run("rev-parse", "--verify", "--quiet", commit+"^2^{tree}")
+	}
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%s is not a snapshot", ref)
+	}
+	s.WorkTree, s.Index = strings.TrimSpace(s.WorkTree), strings.TrimSpace(s.Index)
+	return s, nil
+}
+
+// RestoreSnapshot resets the working tree and the index to the snapshot.
+// Files created since are removed; ignored files are left alone.
+func RestoreSnapshot(s Snapshot) error {
+	err := withTempIndex(func(env []string) error {
+		current, err := workTreeTree(env)
+		if err != nil {
+			return err
+		}
+		// Switch between the trees as checkout does, deleting what s lacks
+		if _, err := runWith(env, "", "read-tree", "-m", "-u", current, s.WorkTree); err != nil {
+			return fmt.Errorf("failed to restore the working tree: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := run("read-tree", "--reset", s.Index); err != nil {
+		return fmt.Errorf("failed to restore the index: %w", err)
+	}
+	run("update-index", "-q", "--refresh") // Fails while files differ from the index, which is expected
+	return nil
+}
+
+// DeleteRef removes a ref, if it exists
+func DeleteRef(ref string) error {
+	_, err := run("update-ref", "-d", ref)
+	return err
+}
+
+// currentTree writes a tree of the working tree
+func currentTree() (string, error) {
+	var tree string
+	err := withTempIndex(func(env []string) (err error) {
+		tree, err = workTreeTree(env)
+		return err
+	})
+	return tree, err
+}
+
+// workTreeTree writes a tree of the working tree by staging everything in the
+// temporary index of env
+func workTreeTree(env []string) (string, error) {
+	if _, err := runWith(env, "", "add", "--all", "--", ":/"); err != nil {
+		return "", fmt.Errorf("failed to save the working tree: %w", err)
+	}
+	tree, err := runWith(env, "", "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to save the working tree: %w", err)
+	}
+	return strings.TrimSpace(tree), nil
+}
+
+// withTempIndex calls fn with the environment of a temporary copy of the
+// index, which keeps its file stat cache so unchanged files are not rehashed
+func withTempIndex(fn func(env []string) error) error {
+	paths, err := ResolveRepoPaths()
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "glimpse-index-")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary index: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	index := filepath.Join(dir, "index")
+	if data, err := os.ReadFile(paths.Index); err == nil {
+		if err := os.WriteFile(index, data, 0600); err != nil {
+			return fmt.Errorf("failed to create a temporary index: %w", err)
+		}
+	}
+	return fn([]string{"GIT_INDEX_FILE=" + index})
+}
@@ -0,0 +1,60 @@
+package git
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	initRepo(t)
+	commitFile(t, ".gitignore", "*.log\n")
+	commitFile(t, "a.txt", "one\n")
+	commitFile(t, "b.txt", "b\n")
+
+	// Staged, unstaged and untracked changes
+	assert.NoError(t, os.WriteFile("a.txt", []byte("two\n"), 0644))
+	gitCmd(t, "add", "a.txt")
+	assert.NoError(t, os.WriteFile("a.txt", []byte("three\n"), 0644))
+	assert.NoError(t, os.WriteFile("notes.txt", []byte("draft\n"), 0644))
+
+	s, err := TakeSnapshot("refs/glimpse/test")
+	assert.NoError(t, err)
+	assert.Equal(t, "three\n", readFile(t, "a.txt"), "taking a snapshot changes nothing")
+	assert.Equal(t, "a.txt", gitCmd(t, "diff", "--cached", "--name-only"))
+
+	// What a fix might do
+	assert.NoError(t, os.WriteFile("a.txt", []byte("fixed\n"), 0644))
+	assert.NoError(t, os.Remove("b.txt"))
+	assert.NoError(t, os.WriteFile("new.txt", []byte("new\n"), 0644))
+	assert.NoError(t, os.WriteFile("debug.log", []byte("log\n"), 0644))
+	gitCmd(t, "add", "a.txt", "new.txt")
+
+	loaded, err := LoadSnapshot("refs/glimpse/test")
+	assert.NoError(t, err)
+	assert.Equal(t, s, loaded)
+	assert.NoError(t, RestoreSnapshot(loaded))
+
+	assert.Equal(t, "three\n", readFile(t, "a.txt"))
+	assert.Equal(t, "b\n", readFile(t, "b.txt"))
+	assert.Equal(t, "draft\n", readFile(t, "notes.txt"))
+	assert.NoFileExists(t, "new.txt")
+	assert.FileExists(t, "debug.log", "ignored files are left alone")
+	staged, err := IndexFile("a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "two\n", staged)
+	assert.Equal(t, "a.txt", gitCmd(t, "diff", "--cached", "--name-only"))
+	assert.Equal(t, "?? notes.txt", gitCmd(t, "status", "--porcelain", "--", "notes.txt"))
+
+	assert.NoError(t, DeleteRef("refs/glimpse/test"))
+	_, err = LoadSnapshot("refs/glimpse/test")
+	assert.Error(t, err)
+}
+
+// readFile returns the content of a file in the working directory
+func readFile(t *testing.T, name string) string {
+	data, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	return string(data)
+}
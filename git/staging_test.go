@@ -1,6 +1,7 @@
 package git
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,4 +25,51 @@ func TestGetStagedDiff(t *testing.T) {
 	// We expect this to not error
 	assert.NoError(t, err)
 	assert.NotNil(t, diffs)
-}
\ No newline at end of file
+}
+
+func TestStagedHashTracksContent(t *testing.T) {
+	initRepo(t)
+	commitFile(t, "a.txt", "one\n")
+
+	assert.NoError(t, os.WriteFile("a.txt", []byte("two\n"), 0644))
+	gitCmd(t, "add", "a.txt")
+	first, err := GetStagedState()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt"}, first.StagedFiles)
+
+	// Same file, same status letter, different content
+	assert.NoError(t, os.WriteFile("a.txt", []byte("three\n"), 0644))
+	gitCmd(t, "add", "a.txt")
+	second, err := GetStagedState()
+	assert.NoError(t, err)
+	assert.Equal(t, first.StagedFiles, second.StagedFiles)
+	assert.NotEqual(t, first.Hash, second.Hash)
+
+	// Unstaged edits do not count
+	assert.NoError(t, os.WriteFile("a.txt", []byte("four\n"), 0644))
+	hash, err := StagedHash()
+	assert.NoError(t, err)
+	assert.Equal(t, second.Hash, hash)
+}
+
+func TestStagedHashTakesNoIndexLock(t *testing.T) {
+	initRepo(t)
+	commitFile(t, "a.txt", "one\n")
+
+	// Held by a concurrent git add or commit
+	assert.NoError(t, os.WriteFile(".git/index.lock", nil, 0644))
+	_, err := StagedHash()
+
+	assert.NoError(t, err)
+}
+
+func TestGetStagedStateWithoutCommits(t *testing.T) {
+	initRepo(t)
+	assert.NoError(t, os.WriteFile("a.txt", []byte("one\n"), 0644))
+	gitCmd(t, "add", "a.txt")
+
+	state, err := GetStagedState()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt"}, state.StagedFiles)
+}
@@ -0,0 +1,42 @@
+package git
+
+import (
+	"fmt"
+	"os"
+)
+
+// Worktree is a temporary checkout linked to the repository, where changes are
+// made without touching the user's working tree
+type Worktree struct {
+	Dir  string
+	Base string // Commit checked out
+}
+
+// AddWorktree checks out commit, detached, in a new temporary directory
+func AddWorktree(commit string) (*Worktree, error) {
+	dir, err := os.MkdirTemp("", "glimpse-worktree-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a worktree: %w", err)
+	}
+	if _, err := run("worktree", "add", "--detach", "--quiet", dir, commit); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to create a worktree: %w", err)
+	}
+	return &Worktree{Dir: dir, Base: commit}, nil
+}
+
+// Diff returns the binary diff of everything changed in the worktree since its
+// commit, new files included and ignored files left out
+func (w *Worktree) Diff() (string, error) {
+	if _, err := runIn(w.Dir, "add", "--all"); err != nil {
+		return "", fmt.Errorf("failed to diff the worktree: %w", err)
+	}
+	return runIn(w.Dir, "diff", "--cached", "--binary", "--no-color", "--no-ext-diff", w.Base)
+}
+
+// Remove deletes the worktree and its files
+func (w *Worktree) Remove() error {
+	_, err := run("worktree", "remove", "--force", w.Dir)
+	os.RemoveAll(w.Dir)
+	return err
+}
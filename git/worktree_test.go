@@ -0,0 +1,37 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorktreeDiffAppliesToCheckout(t *testing.T) {
+	initRepo(t)
+	commitFile(t, "a.txt", "one\n")
+	assert.NoError(t, os.WriteFile("a.txt", []byte("two\n"), 0644))
+	assert.NoError(t, os.WriteFile("notes.txt", []byte("draft\n"), 0644))
+
+	s, err := TakeSnapshot("")
+	assert.NoError(t, err)
+	w, err := AddWorktree(s.Commit)
+	assert.NoError(t, err)
+	assert.Equal(t, "draft\n", readFile(t, filepath.Join(w.Dir, "notes.txt")), "the worktree holds uncommitted files")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(w.Dir, "a.txt"), []byte("fixed\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(w.Dir, "new.txt"), []byte("new\n"), 0644))
+	assert.Equal(t, "two\n", readFile(t, "a.txt"), "the checkout is untouched")
+
+	diff, err := w.Diff()
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "+fixed")
+	assert.Contains(t, diff, "b/new.txt")
+
+	assert.NoError(t, w.Remove())
+	assert.NoDirExists(t, w.Dir)
+	assert.NoError(t, ApplyPatch(diff, false))
+	assert.Equal(t, "fixed\n", readFile(t, "a.txt"))
+	assert.Equal(t, "new\n", readFile(t, "new.txt"))
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/revrost/glimpse/config"
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/history"
+	"github.com/revrost/glimpse/review"
+	"github.com/revrost/glimpse/styles"
+	"github.com/revrost/glimpse/ui"
+)
+
+const historyUsage = `Usage: glimpse history <command>
+
+Commands:
+  list [-n N]                                List recent reviews, newest first
+  show [--prompt] [--diff] [--fix] <review>  Show the findings of a review
+  diff <old> <new>                           Compare the findings of two reviews
+
+Reviews are named by ID (or a unique prefix), "last" or "last~N".`
+
+// runHistoryCommand handles "glimpse history ..." and returns the exit code
+func runHistoryCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, historyUsage)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+	store, err := openHistory(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+	if store == nil {
+		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle("Review history is disabled (history.enabled: false)"))
+		return 1
+	}
+
+	switch args[0] {
+	case "list":
+		return runHistoryList(store, args[1:])
+	case "show":
+		return runHistoryShow(store, args[1:])
+	case "diff":
+		return runHistoryDiff(store, args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Unknown history command %q", args[0])))
+		fmt.Fprintln(os.Stderr, historyUsage)
+		return 2
+	}
+}
+
+// runHistoryList prints the most recent reviews, newest first
+func runHistoryList(store *history.Store, args []string) int {
+	flags := flag.NewFlagSet("history list", flag.ContinueOnError)
+	limit := flags.Int("n", 20, "Number of reviews to list (0 for all)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+	if len(entries) == 0 {
+		fmt.Println(styles.CreateInfoStyle("No reviews in history yet"))
+		return 0
+	}
+
+	shown := 0
+	for i := len(entries) - 1; i >= 0 && (*limit <= 0 || shown < *limit); i-- {
+		entry := entries[i]
+		fmt.Printf("%s  %s  %-22s %s:%s  %s\n",
+			styles.Info.Render(entry.ID),
+			entry.Time.Local().Format("2006-01-02 15:04"),
+			strings.ToLower(entry.Title),
+			entry.Provider, entry.Model,
+			styles.Muted.Render(fmt.Sprintf("%s, %s", fileCount(entry.Files), review.Summary(entry.Findings))),
+		)
+		shown++
+	}
+	return 0
+}
+
+// runHistoryShow prints one review's findings, optionally with its prompt and diff
+func runHistoryShow(store *history.Store, args []string) int {
+	flags := flag.NewFlagSet("history show", flag.ContinueOnError)
+	showPrompt := flags.Bool("prompt", false, "Also print the system prompt and task")
+	showDiff := flags.Bool("diff", false, "Also print the reviewed diff")
+	showFix := flags.Bool("fix", false, "Also print the transcript of the fix")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, historyUsage)
+		return 2
+	}
+
+	entry, err := store.Get(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+
+	fmt.Println(ui.SuccessBox(entry.Title, review.Summary(entry.Findings)))
+	fmt.Println(styles.Muted.Render(fmt.Sprintf("%s  %s  %s:%s", entry.ID, entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Provider, entry.Model)))
+	if entry.StagedHash != "" {
+		fmt.Println(styles.Muted.Render("Staged index " + entry.StagedHash))
+	}
+	fmt.Println(styles.Muted.Render("Files: " + strings.Join(entry.Files, ", ")))
+	printMarkdown(review.FormatMarkdown(entry.Findings))
+	if entry.Fix != nil && !*showFix {
+		fmt.Println(styles.Muted.Render(fmt.Sprintf("Fixed by %s (show the transcript with --fix)", entry.Fix.Agent)))
+	}
+
+	if *showPrompt {
+		fmt.Println(styles.Status.Render("System prompt"))
+		fmt.Println(entry.SystemPrompt)
+		fmt.Println(styles.Status.Render("Task"))
+		fmt.Println(entry.Task)
+	}
+	if *showDiff {
+		fmt.Println(styles.Status.Render("Diff"))
+		fmt.Println(entry.Diff)
+	}
+	if *showFix {
+		if entry.Fix == nil {
+			fmt.Println(styles.Muted.Render("Not fixed"))
+		} else {
+			fmt.Println(styles.Status.Render("Fix by " + entry.Fix.Agent))
+			fmt.Println(entry.Fix.Transcript)
+		}
+	}
+	return 0
+}
+
+// runHistoryDiff compares the findings of two reviews
+func runHistoryDiff(store *history.Store, args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, historyUsage)
+		return 2
+	}
+
+	before, err := store.Get(args[0])
+	if err == nil {
+		var after history.Entry
+		if after, err = store.Get(args[1]); err == nil {
+			printComparison(before, after)
+			return 0
+		}
+	}
+	fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+	return 1
+}
+
+// printComparison prints which findings were fixed, kept and added between two reviews
+func printComparison(before, after history.Entry) {
+	c := history.Compare(before, after)
+
+	fmt.Println(styles.Status.Render(fmt.Sprintf("%s (%s) → %s (%s)",
+		before.ID, before.Time.Local().Format("2006-01-02 15:04"), after.ID, after.Time.Local().Format("2006-01-02 15:04"))))
+	if c.SameDiff {
+		fmt.Println(styles.Muted.Render("Same changes reviewed"))
+	} else {
+		fmt.Println(styles.Muted.Render("The reviewed changes differ"))
+	}
+
+	printSection("Resolved", c.Fixed)
+	printSection("Still open", c.Kept)
+	printSection("New", c.Added)
+}
+
+// printSection prints a titled group of findings
+func printSection(title string, findings []review.Finding) {
+	fmt.Println(styles.Info.Render(fmt.Sprintf("%s (%d)", title, len(findings))))
+	if len(findings) > 0 {
+		printMarkdown(review.FormatMarkdown(findings))
+	}
+}
+
+// printMarkdown renders markdown for the terminal, falling back to plain text
+func printMarkdown(markdown string) {
+	renderer, err := ui.NewMarkdownRenderer()
+	if err != nil {
+		fmt.Println(markdown)
+		return
+	}
+	fmt.Println(renderer.RenderResponse(markdown))
+}
+
+// fileCount renders "1 file" or "N files"
+func fileCount(files []string) string {
+	if len(files) == 1 {
+		return "1 file"
+	}
+	return fmt.Sprintf("%d files", len(files))
+}
+
+// openHistory returns the review history store, or nil when it is disabled
+func openHistory(cfg *config.Config) (*history.Store, error) {
+	if !cfg.History.Enabled {
+		return nil, nil
+	}
+
+	dir := cfg.History.Dir
+	if dir == "" {
+		dir = defaultHistoryDir()
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("could not determine the history directory; set history.dir")
+	}
+	return history.Open(dir, cfg.History.MaxEntries), nil
+}
+
+// defaultHistoryDir is .git/glimpse, or the XDG data dir outside a repository
+func defaultHistoryDir() string {
+	if commonDir, err := git.CommonDir(); err == nil {
+		return filepath.Join(commonDir, "glimpse")
+	}
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "glimpse")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", "glimpse")
+	}
+	return ""
+}
+
+// stagedBaseline returns the latest staged review in the history, or nil when
+// there is none or it was made by another model
+func stagedBaseline(cfg *config.Config) *review.Baseline {
+	store, err := openHistory(cfg)
+	if err != nil || store == nil {
+		return nil
+	}
+	entries, err := store.List()
+	if err != nil {
+		return nil
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Title != stagedReviewTitle || entry.StagedHash == "" {
+			continue
+		}
+		if entry.Provider != cfg.LLM.Provider || entry.Model != cfg.LLM.Model {
+			return nil
+		}
+		diffs, err := git.ParseUnified(entry.Diff)
+		if err != nil {
+			return nil
+		}
+		return &review.Baseline{Diffs: diffs, Findings: entry.Findings}
+	}
+	return nil
+}
+
+// recordReview saves a completed review to the history. Failures are only
+// reported: losing a history entry must not fail the review.
+func recordReview(store *history.Store, cfg *config.Config, req review.Request, result *review.Result) {
+	if store == nil {
+		return
+	}
+
+	var diff strings.Builder
+	files := make([]string, 0, len(req.Diffs))
+	for _, d := range req.Diffs {
+		diff.WriteString(d.Content)
+		files = append(files, d.FilePath)
+	}
+
+	entry, err := store.Append(history.Entry{
+		Title:        req.Title,
+		StagedHash:   req.StagedHash,
+		Provider:     cfg.LLM.Provider,
+		Model:        cfg.LLM.Model,
+		SystemPrompt: review.SystemPrompt(cfg.LLM.SystemPrompt),
+		Task:         req.Task,
+		Diff:         diff.String(),
+		Files:        files,
+		Findings:     result.Findings,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle(fmt.Sprintf("Could not save review to history: %v", err)))
+		return
+	}
+	result.ID = entry.ID
+}
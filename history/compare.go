@@ -0,0 +1,24 @@
+package history
+
+import (
+	"github.com/revrost/glimpse/review"
+)
+
+// Comparison is how the findings of a later review relate to an earlier one
+type Comparison struct {
+	SameDiff bool             // Both reviews saw the same changes
+	Fixed    []review.Finding // Only in the earlier review
+	Kept     []review.Finding // In both, as worded by the later review
+	Added    []review.Finding // Only in the later review
+}
+
+// Compare matches the findings of two reviews with review.Compare
+func Compare(before, after Entry) Comparison {
+	changes := review.Compare(before.Findings, after.Findings)
+	return Comparison{
+		SameDiff: before.Diff == after.Diff,
+		Fixed:    changes.Resolved,
+		Kept:     changes.Open,
+		Added:    changes.New,
+	}
+}
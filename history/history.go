@@ -0,0 +1,230 @@
+// Package history stores past reviews so they can be listed, shown and compared.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/revrost/glimpse/review"
+)
+
+// FileName is the store file inside the history directory
+const FileName = "history.jsonl"
+
+// maxLineSize bounds a single stored review, diff included
+const maxLineSize = 64 << 20
+
+// Entry is one stored review
+type Entry struct {
+	ID           string           `json:"id"`
+	Time         time.Time        `json:"time"`
+	Title        string           `json:"title"`
+	StagedHash   string           `json:"staged_hash,omitempty"`
+	Provider     string           `json:"provider"`
+	Model        string           `json:"model"`
+	SystemPrompt string           `json:"system_prompt"`
+	Task         string           `json:"task"`
+	Diff         string           `json:"diff"` // As sent, with secrets masked
+	Files        []string         `json:"files"`
+	Findings     []review.Finding `json:"findings"`
+	Fix          *Fix             `json:"fix,omitempty"` // Set once fix mode ran on the findings
+}
+
+// Fix records a fix of a review's findings
+type Fix struct {
+	Agent      string `json:"agent"`
+	Transcript string `json:"transcript"` // What the agent printed, or the patches applied natively
+}
+
+// Store is an append-only JSONL file of reviews, oldest first
+type Store struct {
+	path       string
+	maxEntries int
+	mu         sync.Mutex
+}
+
+// Open returns the store in dir. maxEntries > 0 keeps only the most recent entries.
+func Open(dir string, maxEntries int) *Store {
+	return &Store{path: filepath.Join(dir, FileName), maxEntries: maxEntries}
+}
+
+// Path returns the store file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append stores an entry, filling in its ID and time when unset
+func (s *Store) Append(entry Entry) (Entry, error) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if entry.ID == "" {
+		entry.ID = newID(entry)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to encode review: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return Entry{}, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to open history: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return entry, s.prune()
+}
+
+// List returns every stored review, oldest first
+func (s *Store) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// Get returns the review whose ID starts with prefix. "last" is the latest
+// review and "last~N" the one N reviews before it.
+func (s *Store) Get(prefix string) (Entry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if back, ok := strings.CutPrefix(prefix, "last"); ok {
+		n := 0
+		if back != "" {
+			if n, err = strconv.Atoi(strings.TrimPrefix(back, "~")); err != nil || !strings.HasPrefix(back, "~") || n < 0 {
+				return Entry{}, fmt.Errorf("invalid review %q (expected last or last~N)", prefix)
+			}
+		}
+		if n >= len(entries) {
+			return Entry{}, fmt.Errorf("only %d review(s) in history", len(entries))
+		}
+		return entries[len(entries)-1-n], nil
+	}
+
+	var found []Entry
+	for _, entry := range entries {
+		if prefix != "" && strings.HasPrefix(entry.ID, prefix) {
+			found = append(found, entry)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Entry{}, fmt.Errorf("no review %q in history", prefix)
+	case 1:
+		return found[0], nil
+	default:
+		return Entry{}, fmt.Errorf("review ID %q is ambiguous (%d matches)", prefix, len(found))
+	}
+}
+
+// read parses the store file; a missing file is an empty history
+func (s *Store) read() ([]Entry, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", s.path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// prune rewrites the store with the most recent maxEntries reviews
+func (s *Store) prune() error {
+	if s.maxEntries <= 0 {
+		return nil
+	}
+	entries, err := s.read()
+	if err != nil || len(entries) <= s.maxEntries {
+		return err
+	}
+
+	return s.write(entries[len(entries)-s.maxEntries:])
+}
+
+// Update changes the review with the given ID in place
+func (s *Store) Update(id string, change func(*Entry)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			change(&entries[i])
+			return s.write(entries)
+		}
+	}
+	return fmt.Errorf("no review %q in history", id)
+}
+
+// write replaces the store with entries
+func (s *Store) write(entries []Entry) error {
+	var b bytes.Buffer
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		b.Write(append(line, '\n'))
+	}
+
+	// Write then rename, so a crash never leaves a truncated history
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// newID derives a short, unique-enough ID from the review's time and content
+func newID(entry Entry) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\x00%s\x00%s", entry.Time.UnixNano(), entry.Title, entry.Diff)
+	return hex.EncodeToString(h.Sum(nil))[:10]
+}
@@ -0,0 +1,111 @@
+package history
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/revrost/glimpse/review"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreAppendAndGet(t *testing.T) {
+	store := Open(t.TempDir(), 0)
+
+	entries, err := store.List()
+	assert.NoError(t, err)
+	assert.Empty(t, entries, "missing file is an empty history")
+
+	first, err := store.Append(Entry{Title: "STAGED CHANGE REVIEW", Diff: "a"})
+	assert.NoError(t, err)
+	assert.Len(t, first.ID, 10)
+	assert.False(t, first.Time.IsZero())
+	second, err := store.Append(Entry{Title: "STAGED CHANGE REVIEW", Diff: "b", Findings: []review.Finding{{Message: "m"}}})
+	assert.NoError(t, err)
+
+	entries, err = store.List()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "m", entries[1].Findings[0].Message)
+
+	got, err := store.Get(first.ID[:6])
+	assert.NoError(t, err)
+	assert.Equal(t, "a", got.Diff)
+	got, err = store.Get("last")
+	assert.NoError(t, err)
+	assert.Equal(t, second.ID, got.ID)
+	got, err = store.Get("last~1")
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, got.ID)
+
+	_, err = store.Get("last~2")
+	assert.ErrorContains(t, err, "only 2 review(s)")
+	_, err = store.Get("zzz")
+	assert.ErrorContains(t, err, "no review")
+}
+
+func TestStorePrunesOldest(t *testing.T) {
+	store := Open(t.TempDir(), 2)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		_, err := store.Append(Entry{Time: base.Add(time.Duration(i) * time.Minute), Diff: string(rune('a' + i))})
+		assert.NoError(t, err)
+	}
+
+	entries, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "c", entries[0].Diff)
+	assert.Equal(t, "d", entries[1].Diff)
+	assert.NoFileExists(t, store.Path()+".tmp")
+}
+
+func TestStoreUpdate(t *testing.T) {
+	store := Open(t.TempDir(), 0)
+	first, err := store.Append(Entry{Title: "first", Diff: "a"})
+	assert.NoError(t, err)
+	_, err = store.Append(Entry{Title: "second", Diff: "b"})
+	assert.NoError(t, err)
+
+	err = store.Update(first.ID, func(e *Entry) { e.Fix = &Fix{Agent: "crush", Transcript: "done"} })
+
+	assert.NoError(t, err)
+	entries, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, &Fix{Agent: "crush", Transcript: "done"}, entries[0].Fix)
+	assert.Nil(t, entries[1].Fix)
+	assert.ErrorContains(t, store.Update("zzz", func(*Entry) {}), "no review")
+}
+
+func TestStoreReportsCorruptLines(t *testing.T) {
+	store := Open(t.TempDir(), 0)
+	_, err := store.Append(Entry{Diff: "a"})
+	assert.NoError(t, err)
+
+	f, err := os.OpenFile(store.Path(), os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	f.WriteString("{not json\n")
+	f.Close()
+
+	_, err = store.List()
+	assert.ErrorContains(t, err, ":2:")
+}
+
+func TestCompare(t *testing.T) {
+	before := Entry{Diff: "x", Findings: []review.Finding{
+		{File: "a.go", StartLine: 10, Category: "bug", Message: "Nil map write"},
+		{File: "a.go", StartLine: 20, Category: "style", Message: "Long function"},
+	}}
+	after := Entry{Diff: "y", Findings: []review.Finding{
+		{File: "a.go", StartLine: 14, Category: "bug", Message: "nil map  write"},
+		{File: "b.go", StartLine: 1, Category: "security", Message: "SQL injection"},
+	}}
+
+	c := Compare(before, after)
+
+	assert.False(t, c.SameDiff)
+	assert.Equal(t, []review.Finding{before.Findings[1]}, c.Fixed)
+	assert.Equal(t, []review.Finding{after.Findings[0]}, c.Kept, "line shifts and case are ignored")
+	assert.Equal(t, []review.Finding{after.Findings[1]}, c.Added)
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/hook"
+	"github.com/revrost/glimpse/review"
+	"github.com/revrost/glimpse/styles"
+)
+
+const hookUsage = `Usage: glimpse hook <command>
+
+Commands:
+  install [--force]   Install the pre-commit and pre-push hooks
+  uninstall           Remove the hooks, restoring any replaced ones
+  run <hook>          Review what is being committed or pushed (called by the hooks)`
+
+// runHookCommand handles "glimpse hook ..." and returns the exit code
+func runHookCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, hookUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "install":
+		return runHookInstall(args[1:])
+	case "uninstall":
+		return runHookUninstall()
+	case "run":
+		return runHook(args[1:], os.Stdin)
+	default:
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Unknown hook command %q", args[0])))
+		fmt.Fprintln(os.Stderr, hookUsage)
+		return 2
+	}
+}
+
+// runHookInstall writes the hooks into the repository's hooks directory
+func runHookInstall(args []string) int {
+	flags := flag.NewFlagSet("hook install", flag.ContinueOnError)
+	force := flags.Bool("force", false, "Replace existing hooks, keeping a backup")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	dir, err := git.HooksDir()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+
+	installed, err := hook.Install(dir, *force)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+	for _, path := range installed {
+		fmt.Println(styles.CreateSuccessStyle("Installed " + path))
+	}
+	fmt.Println(styles.Muted.Render(fmt.Sprintf("Set %s=1 to bypass the review in an emergency", hook.BypassEnv)))
+	return 0
+}
+
+// runHookUninstall removes the hooks written by runHookInstall
+func runHookUninstall() int {
+	dir, err := git.HooksDir()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+
+	removed, err := hook.Uninstall(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+	if len(removed) == 0 {
+		fmt.Println(styles.CreateInfoStyle("No glimpse hooks installed"))
+	}
+	for _, path := range removed {
+		fmt.Println(styles.CreateSuccessStyle("Removed " + path))
+	}
+	return 0
+}
+
+// runHook reviews the commit or push in progress and returns a non-zero exit
+// code when findings reach the fail-on severity
+func runHook(args []string, stdin io.Reader) int {
+	flags := flag.NewFlagSet("hook run", flag.ContinueOnError)
+	var provider string
+	flags.StringVar(&provider, "provider", "", "LLM provider and model in format 'provider:model'")
+	flags.StringVar(&provider, "p", "", "Alias for --provider")
+	failOn := flags.String("fail-on", "", "Fail the hook on findings at or above this severity (default from hook.fail_on)")
+	format, outputPath := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	out, err := parseOutput(*format, *outputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, hookUsage)
+		return 2
+	}
+	name := flags.Arg(0)
+
+	if hook.Bypassed() {
+		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle(fmt.Sprintf("%s set, skipping glimpse %s review", hook.BypassEnv, name)))
+		return 0
+	}
+
+	cfg, err := loadReviewConfig(provider, "", "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+	if *failOn != "" {
+		cfg.Hook.FailOn = *failOn
+	}
+	threshold, err := review.ParseSeverity(cfg.Hook.FailOn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Invalid fail-on severity: %v", err)))
+		return 1
+	}
+
+	var diffs []git.Diff
+	switch name {
+	case hook.PreCommit:
+		diffs, err = preCommitDiffs()
+	case hook.PrePush:
+		diffs, err = prePushDiffs(stdin)
+	default:
+		err = fmt.Errorf("unsupported hook %q (expected %s or %s)", name, hook.PreCommit, hook.PrePush)
+	}
+	if err == nil {
+		diffs, err = reviewableDiffs(cfg, diffs)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+
+	req := review.Request{
+		Title: strings.ToUpper(name) + " REVIEW",
+		Diffs: diffs,
+		Task:  "Review the changes about to be committed or pushed. Flag bugs, security issues and risks. Be concise.",
+	}
+	if len(diffs) == 0 {
+		if err := out.write(cfg, req, nil); err != nil {
+			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+			return 1
+		}
+		return 0
+	}
+
+	reviewer, err := newReviewer(cfg, newLLMClient(cfg))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if name == hook.PreCommit {
+		req.StagedHash, _ = git.StagedHash()
+	}
+	result, err := reviewer.Review(ctx, req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		printBypassHint()
+		return 1
+	}
+
+	if _, ok := out.emit(cfg, req, result, reviewFilter(cfg)); !ok {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle("Could not reach a verdict on the review"))
+		printBypassHint()
+		return 1
+	}
+
+	blocking := review.Filter{MinSeverity: threshold, Categories: cfg.Review.Categories}.Apply(result.Findings)
+	if len(blocking) > 0 {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf(
+			"glimpse %s failed: %d finding(s) at or above %s", name, len(blocking), threshold,
+		)))
+		printBypassHint()
+		return 1
+	}
+	return 0
+}
+
+// preCommitDiffs returns the staged changes, which are exactly what is being committed
+func preCommitDiffs() ([]git.Diff, error) {
+	return git.GetStagedDiff()
+}
+
+// prePushDiffs returns the changes in the commits being pushed, as listed on stdin
+func prePushDiffs(stdin io.Reader) ([]git.Diff, error) {
+	refs, err := hook.ParsePushRefs(stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []git.Diff
+	for _, ref := range refs {
+		if ref.LocalSHA == git.ZeroSHA {
+			continue // Deleting a remote branch
+		}
+
+		// Diff from where the histories meet, so that commits only the remote
+		// has (e.g. before a force push) are not shown as reverted
+		var base string
+		if ref.RemoteSHA != git.ZeroSHA && git.CommitExists(ref.RemoteSHA) {
+			base, _ = git.MergeBase(ref.RemoteSHA, ref.LocalSHA)
+		}
+		if base == "" {
+			// New remote branch or unrelated remote history: review the commits no remote has yet
+			if base, err = git.PushBase(ref.LocalSHA); err != nil {
+				return nil, err
+			}
+			if base == "" {
+				continue
+			}
+		}
+
+		refDiffs, err := git.GetRangeDiff(base, ref.LocalSHA)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, refDiffs...)
+	}
+	return diffs, nil
+}
+
+// printBypassHint tells the user how to get past a failing hook
+func printBypassHint() {
+	fmt.Fprintln(os.Stderr, styles.Muted.Render(fmt.Sprintf("Set %s=1 to bypass the review", hook.BypassEnv)))
+}
@@ -0,0 +1,137 @@
+package hook
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Names of the hooks Glimpse manages
+const (
+	PreCommit = "pre-commit"
+	PrePush   = "pre-push"
+)
+
+// Names lists every hook installed by Install
+var Names = []string{PreCommit, PrePush}
+
+// BypassEnv skips the review when set to a non-empty value other than "0"
+const BypassEnv = "GLIMPSE_SKIP"
+
+// marker identifies hook scripts written by Glimpse
+const marker = "# glimpse-hook"
+
+// backupSuffix is appended to existing hooks replaced with --force
+const backupSuffix = ".pre-glimpse"
+
+// script returns the hook script for the named hook
+func script(name string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s: managed by glimpse, remove with "glimpse hook uninstall"
+# Set %s=1 to bypass the review in an emergency.
+exec glimpse hook run %s "$@"
+`, marker, BypassEnv, name)
+}
+
+// Bypassed reports whether the bypass env var is set
+func Bypassed() bool {
+	value := os.Getenv(BypassEnv)
+	return value != "" && value != "0"
+}
+
+// Install writes the Glimpse hooks into dir. Existing hooks from other tools
+// are refused unless force is set, in which case they are backed up and
+// restored by Uninstall.
+func Install(dir string, force bool) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	for _, name := range Names {
+		path := filepath.Join(dir, name)
+		existing, err := os.ReadFile(path)
+		if err == nil && !isManaged(existing) && !force {
+			return nil, fmt.Errorf("%s already exists; rerun with --force to replace it (it will be backed up)", path)
+		}
+	}
+
+	var installed []string
+	for _, name := range Names {
+		path := filepath.Join(dir, name)
+		if existing, err := os.ReadFile(path); err == nil && !isManaged(existing) {
+			if err := os.Rename(path, path+backupSuffix); err != nil {
+				return installed, fmt.Errorf("failed to back up %s: %w", path, err)
+			}
+		}
+		if err := os.WriteFile(path, []byte(script(name)), 0755); err != nil {
+			return installed, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		installed = append(installed, path)
+	}
+	return installed, nil
+}
+
+// Uninstall removes the Glimpse hooks from dir and restores any backed up hooks.
+// Hooks not written by Glimpse are left alone.
+func Uninstall(dir string) ([]string, error) {
+	var removed []string
+	for _, name := range Names {
+		path := filepath.Join(dir, name)
+		existing, err := os.ReadFile(path)
+		if err != nil || !isManaged(existing) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed = append(removed, path)
+
+		if _, err := os.Stat(path + backupSuffix); err == nil {
+			if err := os.Rename(path+backupSuffix, path); err != nil {
+				return removed, fmt.Errorf("failed to restore %s: %w", path, err)
+			}
+		}
+	}
+	return removed, nil
+}
+
+// isManaged reports whether a hook script was written by Glimpse
+func isManaged(content []byte) bool {
+	return strings.Contains(string(content), marker)
+}
+
+// PushRef is one line of the refs git passes to pre-push on stdin
+type PushRef struct {
+	LocalRef  string
+	LocalSHA  string
+	RemoteRef string
+	RemoteSHA string
+}
+
+// ParsePushRefs reads the "<local ref> <local sha> <remote ref> <remote sha>" lines given to pre-push
+func ParsePushRefs(r io.Reader) ([]PushRef, error) {
+	var refs []PushRef
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected pre-push input: %q", scanner.Text())
+		}
+		refs = append(refs, PushRef{
+			LocalRef:  fields[0],
+			LocalSHA:  fields[1],
+			RemoteRef: fields[2],
+			RemoteSHA: fields[3],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pre-push input: %w", err)
+	}
+	return refs, nil
+}
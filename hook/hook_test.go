@@ -0,0 +1,83 @@
+package hook
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallAndUninstall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hooks")
+
+	installed, err := Install(dir, false)
+	assert.NoError(t, err)
+	assert.Len(t, installed, 2)
+
+	content, err := os.ReadFile(filepath.Join(dir, PrePush))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `exec glimpse hook run pre-push "$@"`)
+
+	info, err := os.Stat(filepath.Join(dir, PreCommit))
+	assert.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0100, "hook is executable")
+
+	// Reinstalling over our own hooks needs no --force
+	_, err = Install(dir, false)
+	assert.NoError(t, err)
+
+	removed, err := Uninstall(dir)
+	assert.NoError(t, err)
+	assert.Len(t, removed, 2)
+	assert.NoFileExists(t, filepath.Join(dir, PreCommit))
+}
+
+func TestInstallKeepsForeignHooks(t *testing.T) {
+	dir := t.TempDir()
+	foreign := filepath.Join(dir, PreCommit)
+	assert.NoError(t, os.WriteFile(foreign, []byte("#!/bin/sh\nmake lint\n"), 0755))
+
+	_, err := Install(dir, false)
+	assert.ErrorContains(t, err, "--force")
+	assert.NoFileExists(t, filepath.Join(dir, PrePush), "nothing installed on refusal")
+
+	_, err = Install(dir, true)
+	assert.NoError(t, err)
+	backup, err := os.ReadFile(foreign + backupSuffix)
+	assert.NoError(t, err)
+	assert.Contains(t, string(backup), "make lint")
+
+	_, err = Uninstall(dir)
+	assert.NoError(t, err)
+	restored, err := os.ReadFile(foreign)
+	assert.NoError(t, err)
+	assert.Contains(t, string(restored), "make lint")
+	assert.NoFileExists(t, foreign+backupSuffix)
+}
+
+func TestBypassed(t *testing.T) {
+	t.Setenv(BypassEnv, "")
+	assert.False(t, Bypassed())
+	t.Setenv(BypassEnv, "0")
+	assert.False(t, Bypassed())
+	t.Setenv(BypassEnv, "1")
+	assert.True(t, Bypassed())
+}
+
+func TestParsePushRefs(t *testing.T) {
+	input := "refs/heads/main 1111111111111111111111111111111111111111 refs/heads/main 2222222222222222222222222222222222222222\n\n"
+
+	refs, err := ParsePushRefs(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, []PushRef{{
+		LocalRef:  "refs/heads/main",
+		LocalSHA:  "1111111111111111111111111111111111111111",
+		RemoteRef: "refs/heads/main",
+		RemoteSHA: "2222222222222222222222222222222222222222",
+	}}, refs)
+
+	_, err = ParsePushRefs(strings.NewReader("garbage\n"))
+	assert.Error(t, err)
+}
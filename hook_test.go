@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/hook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrePushDiffsSkipsDeletedBranches(t *testing.T) {
+	stdin := strings.NewReader("(delete) " + git.ZeroSHA + " refs/heads/old 1111111111111111111111111111111111111111\n")
+
+	diffs, err := prePushDiffs(stdin)
+
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+func TestPrePushDiffsFromMergeBase(t *testing.T) {
+	initFixRepo(t)
+	commit := func(name string) string {
+		assert.NoError(t, os.WriteFile(name, []byte(name+"\n"), 0644))
+		assert.NoError(t, exec.Command("git", "add", name).Run())
+		assert.NoError(t, exec.Command("git", "-c", "user.name=t", "-c", "user.email=t@t", "commit", "-qm", name).Run())
+		out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+		assert.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+	assert.NoError(t, exec.Command("git", "checkout", "-q", "-b", "remote").Run())
+	remote := commit("remote.go")
+	assert.NoError(t, exec.Command("git", "checkout", "-q", "-").Run())
+	local := commit("local.go")
+
+	diffs, err := prePushDiffs(strings.NewReader("refs/heads/main " + local + " refs/heads/main " + remote + "\n"))
+
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 1, "commits only the remote has are not shown as reverted")
+	assert.Equal(t, "local.go", diffs[0].FilePath)
+}
+
+func TestRunHookBypass(t *testing.T) {
+	t.Setenv(hook.BypassEnv, "1")
+
+	assert.Equal(t, 0, runHook([]string{"pre-commit"}, strings.NewReader("")))
+}
+
+func TestRunHookCommandUsage(t *testing.T) {
+	assert.Equal(t, 2, runHookCommand(nil))
+	assert.Equal(t, 2, runHookCommand([]string{"bogus"}))
+	assert.Equal(t, 2, runHook(nil, strings.NewReader("")))
+}
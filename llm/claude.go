@@ -0,0 +1,170 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// claudeProvider talks to the Anthropic Messages API
+type claudeProvider struct {
+	endpoint   string
+	apiKey     string
+	model      string
+	maxTokens  int
+	httpClient *http.Client
+}
+
+// newClaudeProvider creates the Anthropic Claude provider
+func newClaudeProvider(config Config, httpClient *http.Client) Provider {
+	// Using claude-3-5-sonnet as default model if not specified
+	model := config.Model
+	if model == "" {
+		model = "claude-3-5-sonnet-20241022"
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com/v1"
+	}
+
+	return &claudeProvider{
+		endpoint:   strings.TrimSuffix(baseURL, "/") + "/messages",
+		apiKey:     config.APIKey,
+		model:      model,
+		maxTokens:  4096,
+		httpClient: httpClient,
+	}
+}
+
+// Name implements Provider
+func (p *claudeProvider) Name() string {
+	return "claude"
+}
+
+type claudeMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type claudeRequest struct {
+	Model     string          `json:"model"`
+	MaxTokens int             `json:"max_tokens"`
+	Messages  []claudeMessage `json:"messages"`
+	System    string          `json:"system,omitempty"`
+	Stream    bool            `json:"stream"`
+}
+
+type claudeResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Error      *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type claudeEvent struct {
+	Type  string `json:"type"`
+	Delta *struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Generate implements Provider
+func (p *claudeProvider) Generate(ctx context.Context, req GenerateRequest) (string, error) {
+	// Claude doesn't use system role in messages
+	payload := claudeRequest{
+		Model:     p.model,
+		MaxTokens: p.maxTokens,
+		Messages: []claudeMessage{
+			{Role: "user", Content: req.Context + "\n\n" + req.Task},
+		},
+		System: req.SystemPrompt,
+		Stream: req.Stream,
+	}
+
+	headers := map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": "2023-06-01",
+	}
+
+	resp, err := postJSON(ctx, p.httpClient, p.endpoint, headers, payload)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if req.Stream {
+		return p.readStream(resp, req.streamWriter())
+	}
+
+	respBody, err := readBody(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var claudeResp claudeResponse
+	if err := json.Unmarshal(respBody, &claudeResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if claudeResp.Error != nil {
+		return "", fmt.Errorf("API error: %s", claudeResp.Error.Message)
+	}
+
+	if len(claudeResp.Content) == 0 {
+		return "", fmt.Errorf("no response from API")
+	}
+
+	return claudeResp.Content[0].Text, nil
+}
+
+// readStream prints a Claude SSE stream to out and returns the full content
+func (p *claudeProvider) readStream(resp *http.Response, out io.Writer) (string, error) {
+	if resp.StatusCode != http.StatusOK {
+		if _, err := readBody(resp); err != nil {
+			return "", err
+		}
+	}
+
+	var fullContent strings.Builder
+
+	err := readSSE(resp.Body, func(data string) (bool, error) {
+		var event claudeEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return false, nil // Skip invalid chunks
+		}
+
+		// Handle error events
+		if event.Error != nil {
+			return true, fmt.Errorf("API error: %s", event.Error.Message)
+		}
+
+		// Handle content_block_delta events (streaming tokens)
+		if event.Type == "content_block_delta" && event.Delta != nil && event.Delta.Type == "text_delta" {
+			fmt.Fprint(out, event.Delta.Text)
+			fullContent.WriteString(event.Delta.Text)
+		}
+
+		// Handle message_stop event (end of stream)
+		return event.Type == "message_stop", nil
+	})
+
+	fmt.Println() // Newline after streaming
+
+	if err != nil {
+		return "", err
+	}
+	return fullContent.String(), nil
+}
@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error kinds returned by providers. Use errors.Is to test for them.
+var (
+	ErrRateLimited = errors.New("rate limited")
+	ErrAuth        = errors.New("authentication failed")
+	ErrQuota       = errors.New("quota exceeded")
+	ErrServer      = errors.New("server error")
+)
+
+// APIError is returned when a provider answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+	kind       error
+}
+
+// newAPIError classifies an error response by status code and message
+func newAPIError(statusCode int, message string) *APIError {
+	var kind error
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		kind = ErrAuth
+	case statusCode == http.StatusPaymentRequired:
+		kind = ErrQuota
+	case statusCode == http.StatusTooManyRequests && isQuotaMessage(message):
+		kind = ErrQuota
+	case statusCode == http.StatusTooManyRequests:
+		kind = ErrRateLimited
+	case statusCode >= 500:
+		kind = ErrServer
+	}
+	return &APIError{StatusCode: statusCode, Message: message, kind: kind}
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	if e.kind == nil {
+		return fmt.Sprintf("API error (%d): %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("API error (%d, %s): %s", e.StatusCode, e.kind, e.Message)
+}
+
+// Unwrap exposes the error kind to errors.Is
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
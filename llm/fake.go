@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake is an in-memory Provider for tests. It records every request and
+// answers with Content, or with Err when set.
+type Fake struct {
+	Content string
+	Err     error
+	// Respond, when set, overrides Content and Err
+	Respond func(req GenerateRequest) (string, error)
+
+	mu       sync.Mutex
+	requests []GenerateRequest
+}
+
+// Name implements Provider
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+// Generate implements Provider
+func (f *Fake) Generate(ctx context.Context, req GenerateRequest) (string, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if f.Respond != nil {
+		return f.Respond(req)
+	}
+	return f.Content, f.Err
+}
+
+// Requests returns a copy of the requests received so far
+func (f *Fake) Requests() []GenerateRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]GenerateRequest(nil), f.requests...)
+}
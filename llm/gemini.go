@@ -0,0 +1,224 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// geminiProvider talks to the Google Gemini generateContent API
+type geminiProvider struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// newGeminiProvider creates the Google Gemini provider
+func newGeminiProvider(config Config, httpClient *http.Client) Provider {
+	// Using gemini-2.0-flash as default model if not specified
+	model := config.Model
+	if model == "" {
+		model = "gemini-2.0-flash"
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = "https://generativelanguage.googleapis.com/v1beta"
+	}
+
+	return &geminiProvider{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     config.APIKey,
+		model:      model,
+		httpClient: httpClient,
+	}
+}
+
+// Name implements Provider
+func (p *geminiProvider) Name() string {
+	return "gemini"
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent         `json:"contents"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiGenerationConfig struct {
+	ResponseMimeType string `json:"responseMimeType,omitempty"`
+}
+
+type geminiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	Error *geminiError `json:"error"`
+}
+
+// Generate implements Provider
+func (p *geminiProvider) Generate(ctx context.Context, req GenerateRequest) (string, error) {
+	payload := geminiRequest{
+		Contents: []geminiContent{
+			{Role: "user", Parts: []geminiPart{{Text: req.Context + "\n\n" + req.Task}}},
+		},
+	}
+	if req.SystemPrompt != "" {
+		payload.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: req.SystemPrompt}}}
+	}
+	if req.JSONSchema != nil {
+		// Gemini schemas are an OpenAPI subset, so rely on JSON mode plus the prompt
+		payload.GenerationConfig = &geminiGenerationConfig{ResponseMimeType: "application/json"}
+	}
+
+	method := "generateContent"
+	if req.Stream {
+		method = "streamGenerateContent?alt=sse"
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:%s", p.baseURL, p.model, method)
+
+	headers := map[string]string{"x-goog-api-key": p.apiKey}
+
+	resp, err := postJSON(ctx, p.httpClient, endpoint, headers, payload)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", p.readError(resp)
+	}
+
+	if req.Stream {
+		return p.readStream(resp, req.streamWriter())
+	}
+
+	respBody, err := readBody(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var geminiResp geminiResponse
+	if err := json.Unmarshal(respBody, &geminiResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	text, _, err := geminiResp.text()
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		return "", fmt.Errorf("no response from API")
+	}
+	return text, nil
+}
+
+// readStream prints a Gemini SSE stream to out and returns the full content
+func (p *geminiProvider) readStream(resp *http.Response, out io.Writer) (string, error) {
+	var fullContent strings.Builder
+
+	err := readSSE(resp.Body, func(data string) (bool, error) {
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, nil // Skip invalid chunks
+		}
+
+		text, done, err := chunk.text()
+		if err != nil {
+			return true, err
+		}
+		fmt.Fprint(out, text)
+		fullContent.WriteString(text)
+		return done, nil
+	})
+
+	fmt.Println() // Newline after streaming
+
+	if err != nil {
+		return "", err
+	}
+	return fullContent.String(), nil
+}
+
+// readError maps a non-200 Gemini response to a descriptive error
+func (p *geminiProvider) readError(resp *http.Response) error {
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var geminiResp geminiResponse
+	if err := json.Unmarshal(respBody, &geminiResp); err != nil || geminiResp.Error == nil {
+		return newAPIError(resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return geminiResp.Error.asError(p.model)
+}
+
+// text extracts the candidate text, reporting whether generation has finished
+func (r *geminiResponse) text() (string, bool, error) {
+	if r.Error != nil {
+		return "", true, r.Error.asError("")
+	}
+	if r.PromptFeedback != nil && r.PromptFeedback.BlockReason != "" {
+		return "", true, fmt.Errorf("API error: prompt blocked by Gemini (%s)", r.PromptFeedback.BlockReason)
+	}
+	if len(r.Candidates) == 0 {
+		return "", false, nil
+	}
+
+	candidate := r.Candidates[0]
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		text.WriteString(part.Text)
+	}
+
+	switch candidate.FinishReason {
+	case "", "STOP", "MAX_TOKENS", "FINISH_REASON_UNSPECIFIED":
+		return text.String(), candidate.FinishReason != "", nil
+	default:
+		// SAFETY, RECITATION, BLOCKLIST, PROHIBITED_CONTENT, ...
+		return "", true, fmt.Errorf("API error: response blocked by Gemini (%s)", candidate.FinishReason)
+	}
+}
+
+// asError converts a Gemini error payload into a typed, user-facing error
+func (e *geminiError) asError(model string) error {
+	apiErr := newAPIError(e.Code, e.Message)
+
+	switch {
+	case e.Status == "UNAUTHENTICATED" || e.Status == "PERMISSION_DENIED" || strings.Contains(e.Message, "API key"):
+		apiErr.kind = ErrAuth
+		apiErr.Message = "check GEMINI_API_KEY: " + e.Message
+	case e.Status == "RESOURCE_EXHAUSTED" && apiErr.kind != ErrQuota:
+		apiErr.kind = ErrRateLimited
+		apiErr.Message = "Gemini quota or rate limit exceeded: " + e.Message
+	case e.Status == "NOT_FOUND":
+		apiErr.Message = fmt.Sprintf("model %q not found: %s", model, e.Message)
+	case apiErr.kind == nil:
+		apiErr.Message = fmt.Sprintf("%s (%s)", e.Message, e.Status)
+	}
+	return apiErr
+}
@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestGemini points a Gemini provider at an httptest stand-in
+func newTestGemini(t *testing.T, handler http.HandlerFunc) *geminiProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider := newGeminiProvider(Config{Model: "gemini-test", APIKey: "test-key"}, server.Client()).(*geminiProvider)
+	provider.baseURL = server.URL
+	return provider
+}
+
+func TestGeminiGenerateContent(t *testing.T) {
+	provider := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/gemini-test:generateContent", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("x-goog-api-key"))
+
+		var payload geminiRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "sys", payload.SystemInstruction.Parts[0].Text)
+		assert.Equal(t, "user", payload.Contents[0].Role)
+		assert.Equal(t, "ctx\n\ntask", payload.Contents[0].Parts[0].Text)
+
+		fmt.Fprint(w, `{"candidates":[{"content":{"parts":[{"text":"no "},{"text":"issues"}]},"finishReason":"STOP"}]}`)
+	})
+
+	content, err := provider.Generate(context.Background(), GenerateRequest{SystemPrompt: "sys", Context: "ctx", Task: "task"})
+	assert.NoError(t, err)
+	assert.Equal(t, "no issues", content)
+}
+
+func TestGeminiStreamGenerateContent(t *testing.T) {
+	provider := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/gemini-test:streamGenerateContent", r.URL.Path)
+		assert.Equal(t, "sse", r.URL.Query().Get("alt"))
+
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"foo\"}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"bar\"}]},\"finishReason\":\"STOP\"}]}\n\n")
+	})
+
+	content, err := provider.Generate(context.Background(), GenerateRequest{Stream: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "foobar", content)
+}
+
+func TestGeminiErrorMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected string
+	}{
+		{"auth", 400, `{"error":{"code":400,"message":"API key not valid.","status":"INVALID_ARGUMENT"}}`, "authentication failed"},
+		{"permission", 403, `{"error":{"code":403,"message":"denied","status":"PERMISSION_DENIED"}}`, "authentication failed"},
+		{"quota", 429, `{"error":{"code":429,"message":"quota","status":"RESOURCE_EXHAUSTED"}}`, "quota or rate limit"},
+		{"model", 404, `{"error":{"code":404,"message":"missing","status":"NOT_FOUND"}}`, `model "gemini-test" not found`},
+		{"html", 502, `<html>bad gateway</html>`, "bad gateway"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			})
+
+			_, err := provider.Generate(context.Background(), GenerateRequest{})
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestGeminiBlockedResponse(t *testing.T) {
+	provider := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"promptFeedback":{"blockReason":"SAFETY"}}`)
+	})
+
+	_, err := provider.Generate(context.Background(), GenerateRequest{})
+	assert.ErrorContains(t, err, "prompt blocked by Gemini (SAFETY)")
+}
+
+func TestGeminiJSONMode(t *testing.T) {
+	provider := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		var payload geminiRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "application/json", payload.GenerationConfig.ResponseMimeType)
+
+		fmt.Fprint(w, `{"candidates":[{"content":{"parts":[{"text":"{\"findings\":[]}"}]},"finishReason":"STOP"}]}`)
+	})
+
+	content, err := provider.Generate(context.Background(), GenerateRequest{JSONSchema: map[string]interface{}{"type": "object"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"findings":[]}`, content)
+}
@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -37,7 +38,7 @@ func TestGenerateOpenAIRequest(t *testing.T) {
 	}
 	
 	// This will fail due to invalid API key, but tests the request format
-	respChan := client.Generate(req)
+	respChan := client.Generate(context.Background(), req)
 	resp := <-respChan
 	
 	assert.Error(t, resp.Error)
@@ -60,7 +61,7 @@ func TestGenerateUnsupportedProvider(t *testing.T) {
 	}
 	
 	// This should fail due to unsupported provider
-	respChan := client.Generate(req)
+	respChan := client.Generate(context.Background(), req)
 	resp := <-respChan
 	
 	assert.Error(t, resp.Error)
@@ -83,7 +84,7 @@ func TestGenerateClaudeRequest(t *testing.T) {
 	}
 	
 	// This will fail due to invalid API key, but tests the request format
-	respChan := client.Generate(req)
+	respChan := client.Generate(context.Background(), req)
 	resp := <-respChan
 	
 	assert.Error(t, resp.Error)
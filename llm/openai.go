@@ -0,0 +1,266 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/revrost/glimpse/styles"
+)
+
+// openAIProvider talks to any endpoint implementing the OpenAI chat completions API
+type openAIProvider struct {
+	name        string
+	endpoint    string
+	apiKey      string
+	model       string
+	temperature *float64
+	headers     map[string]string
+	httpClient  *http.Client
+	// jsonSchema enables structured outputs; other servers get plain JSON mode
+	jsonSchema bool
+}
+
+// newOpenAIProvider creates the OpenAI provider
+func newOpenAIProvider(config Config, httpClient *http.Client) Provider {
+	return &openAIProvider{
+		name:       "openai",
+		endpoint:   chatCompletionsURL(config.BaseURL, "https://api.openai.com/v1"),
+		apiKey:     config.APIKey,
+		model:      config.Model,
+		httpClient: httpClient,
+		jsonSchema: true,
+	}
+}
+
+// newOpenAICompatibleProvider creates a provider for self-hosted or third-party
+// endpoints speaking the OpenAI API (vLLM, llama.cpp, OpenRouter, ...).
+// llm.base_url is required since there is no sensible default.
+func newOpenAICompatibleProvider(config Config, httpClient *http.Client) Provider {
+	return &openAIProvider{
+		name:       "openai-compatible",
+		endpoint:   chatCompletionsURL(config.BaseURL, ""),
+		apiKey:     config.APIKey,
+		model:      config.Model,
+		httpClient: httpClient,
+	}
+}
+
+// newOllamaProvider creates a provider for a local Ollama server
+func newOllamaProvider(config Config, httpClient *http.Client) Provider {
+	return &openAIProvider{
+		name:       "ollama",
+		endpoint:   chatCompletionsURL(config.BaseURL, "http://localhost:11434/v1"),
+		apiKey:     config.APIKey,
+		model:      config.Model,
+		httpClient: httpClient,
+	}
+}
+
+// newZAIProvider creates the Z.AI provider (OpenAI compatible)
+func newZAIProvider(config Config, httpClient *http.Client) Provider {
+	// Using GLM-4.6 as default model if not specified
+	model := config.Model
+	if model == "" {
+		model = "glm-4.6"
+	}
+
+	temperature := 1.0
+	return &openAIProvider{
+		name:        "zai",
+		endpoint:    chatCompletionsURL(config.BaseURL, "https://api.z.ai/api/coding/paas/v4"),
+		apiKey:      config.APIKey,
+		model:       model,
+		temperature: &temperature,
+		headers:     map[string]string{"Accept-Language": "en-US,en"},
+		httpClient:  httpClient,
+	}
+}
+
+// Name implements Provider
+func (p *openAIProvider) Name() string {
+	return p.name
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	Temperature    *float64              `json:"temperature,omitempty"`
+	Stream         bool                  `json:"stream"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string                 `json:"name"`
+	Schema map[string]interface{} `json:"schema"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type openAIChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+// Generate implements Provider
+func (p *openAIProvider) Generate(ctx context.Context, req GenerateRequest) (string, error) {
+	if p.endpoint == "" {
+		return "", fmt.Errorf("%s provider requires llm.base_url (e.g. http://localhost:8000/v1)", p.name)
+	}
+	if p.model == "" {
+		return "", fmt.Errorf("%s provider requires a model", p.name)
+	}
+
+	payload := openAIRequest{
+		Model: p.model,
+		Messages: []openAIMessage{
+			{Role: "system", Content: req.SystemPrompt},
+			{Role: "user", Content: req.Context + "\n\n" + req.Task},
+		},
+		Temperature: p.temperature,
+		Stream:      req.Stream,
+	}
+
+	if req.JSONSchema != nil {
+		if p.jsonSchema {
+			payload.ResponseFormat = &openAIResponseFormat{
+				Type:       "json_schema",
+				JSONSchema: &openAIJSONSchema{Name: "response", Schema: req.JSONSchema},
+			}
+		} else {
+			payload.ResponseFormat = &openAIResponseFormat{Type: "json_object"}
+		}
+	}
+
+	// Local servers usually run without authentication
+	headers := make(map[string]string)
+	if p.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+	for key, value := range p.headers {
+		headers[key] = value
+	}
+
+	resp, err := postJSON(ctx, p.httpClient, p.endpoint, headers, payload)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if req.Stream {
+		return p.readStream(resp, req.streamWriter())
+	}
+
+	respBody, err := readBody(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var openAIResp openAIResponse
+	if err := json.Unmarshal(respBody, &openAIResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if openAIResp.Error != nil {
+		return "", fmt.Errorf("API error: %s", openAIResp.Error.Message)
+	}
+
+	if len(openAIResp.Choices) == 0 {
+		return "", fmt.Errorf("no response from API")
+	}
+
+	return openAIResp.Choices[0].Message.Content, nil
+}
+
+// readStream prints an OpenAI SSE stream to out and returns the full content
+func (p *openAIProvider) readStream(resp *http.Response, out io.Writer) (string, error) {
+	if resp.StatusCode != http.StatusOK {
+		if _, err := readBody(resp); err != nil {
+			return "", err
+		}
+	}
+
+	var fullContent strings.Builder
+	var hasReasoning bool
+
+	err := readSSE(resp.Body, func(data string) (bool, error) {
+		// Check for end of stream
+		if data == "[DONE]" {
+			return true, nil
+		}
+
+		var ch openAIChunk
+		if err := json.Unmarshal([]byte(data), &ch); err != nil {
+			return false, nil // Skip invalid chunks
+		}
+
+		if len(ch.Choices) == 0 {
+			return false, nil
+		}
+
+		delta := ch.Choices[0].Delta
+
+		// Handle reasoning content (for o1 models)
+		if delta.ReasoningContent != "" {
+			if !hasReasoning {
+				fmt.Println(styles.Muted.Render("Thought:"))
+				hasReasoning = true
+			}
+			fmt.Print(styles.Muted.Render(delta.ReasoningContent))
+		}
+
+		// Handle regular content
+		if delta.Content != "" {
+			if hasReasoning && fullContent.Len() == 0 {
+				fmt.Println() // End reasoning section
+				fmt.Println(styles.Info.Render("Response:"))
+			}
+			fmt.Fprint(out, delta.Content)
+			fullContent.WriteString(delta.Content)
+		}
+		return false, nil
+	})
+
+	fmt.Println() // Newline after streaming
+
+	if err != nil {
+		return "", err
+	}
+	return fullContent.String(), nil
+}
+
+// chatCompletionsURL builds the chat completions endpoint from a base URL,
+// falling back to the provider default when no base URL is configured
+func chatCompletionsURL(baseURL, defaultBaseURL string) string {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	if baseURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/chat/completions"
+}
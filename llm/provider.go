@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Provider is an LLM backend capable of answering a GenerateRequest
+type Provider interface {
+	// Name returns the provider identifier used in configuration (e.g. "openai")
+	Name() string
+	// Generate sends the request to the backend and returns the full response text.
+	// Streaming providers write tokens to req.StreamTo, or stdout, as they arrive
+	// when req.Stream is set.
+	Generate(ctx context.Context, req GenerateRequest) (string, error)
+}
+
+// Factory builds a Provider from the client configuration
+type Factory func(config Config, httpClient *http.Client) Provider
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a provider available under the given name.
+// Registering an existing name replaces the previous factory.
+func Register(name string, factory Factory) {
+	if name == "" || factory == nil {
+		panic("llm: Register requires a name and a factory")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Lookup returns the factory registered under name
+func Lookup(name string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[name]
+	return factory, ok
+}
+
+// Providers returns the sorted names of all registered providers
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newProvider resolves the configured provider from the registry
+func newProvider(config Config, httpClient *http.Client) (Provider, error) {
+	factory, ok := Lookup(config.Provider)
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider: %s", config.Provider)
+	}
+	return factory(config, httpClient), nil
+}
+
+func init() {
+	Register("openai", newOpenAIProvider)
+	Register("openai-compatible", newOpenAICompatibleProvider)
+	Register("ollama", newOllamaProvider)
+	Register("zai", newZAIProvider)
+	Register("claude", newClaudeProvider)
+	Register("gemini", newGeminiProvider)
+}
@@ -0,0 +1,223 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinProvidersRegistered(t *testing.T) {
+	for _, name := range []string{"openai", "zai", "claude", "gemini"} {
+		factory, ok := Lookup(name)
+		assert.True(t, ok, "provider %s should be registered", name)
+		assert.Equal(t, name, factory(Config{}, http.DefaultClient).Name())
+	}
+	assert.Contains(t, Providers(), "openai")
+}
+
+func TestRegisterCustomProvider(t *testing.T) {
+	fake := &Fake{Content: "custom review"}
+	Register("custom-test", func(Config, *http.Client) Provider { return fake })
+
+	client := New(Config{Provider: "custom-test", Model: "m"})
+	resp := <-client.Generate(context.Background(), GenerateRequest{Task: "Review", Stream: true})
+
+	assert.NoError(t, resp.Error)
+	assert.Equal(t, "custom review", resp.Content)
+	assert.Len(t, fake.Requests(), 1)
+}
+
+func TestNewWithProvider(t *testing.T) {
+	fake := &Fake{Err: errors.New("boom")}
+	client := NewWithProvider(Config{Provider: "ignored"}, fake)
+
+	resp := <-client.Generate(context.Background(), GenerateRequest{SystemPrompt: "sys", Task: "Review", Stream: true})
+
+	assert.EqualError(t, resp.Error, "boom")
+	assert.Equal(t, "sys", fake.Requests()[0].SystemPrompt)
+}
+
+func TestOpenAIProviderRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var payload openAIRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "gpt-4o", payload.Model)
+		assert.Equal(t, "system", payload.Messages[0].Role)
+		assert.Equal(t, "ctx\n\ntask", payload.Messages[1].Content)
+
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"looks fine"}}]}`)
+	}))
+	defer server.Close()
+
+	provider := newOpenAIProvider(Config{Model: "gpt-4o", APIKey: "test-key"}, server.Client()).(*openAIProvider)
+	provider.endpoint = server.URL
+
+	content, err := provider.Generate(context.Background(), GenerateRequest{SystemPrompt: "sys", Context: "ctx", Task: "task"})
+	assert.NoError(t, err)
+	assert.Equal(t, "looks fine", content)
+}
+
+func TestOpenAIProviderStreaming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"hel\"}}]}\n\n")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider := newZAIProvider(Config{}, server.Client()).(*openAIProvider)
+	provider.endpoint = server.URL
+
+	var streamed strings.Builder
+	content, err := provider.Generate(context.Background(), GenerateRequest{Stream: true, StreamTo: &streamed})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", content)
+	assert.Equal(t, "hello", streamed.String())
+}
+
+func TestOpenAIProviderHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<html>bad gateway</html>", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	provider := newOpenAIProvider(Config{Model: "gpt-4o"}, server.Client()).(*openAIProvider)
+	provider.endpoint = server.URL
+
+	_, err := provider.Generate(context.Background(), GenerateRequest{})
+	assert.ErrorContains(t, err, "bad gateway")
+}
+
+func TestClaudeProviderRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("x-api-key"))
+
+		var payload claudeRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "sys", payload.System)
+		assert.Equal(t, "claude-3-5-sonnet-20241022", payload.Model)
+
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"all good"}]}`)
+	}))
+	defer server.Close()
+
+	provider := newClaudeProvider(Config{APIKey: "test-key"}, server.Client()).(*claudeProvider)
+	provider.endpoint = server.URL
+
+	content, err := provider.Generate(context.Background(), GenerateRequest{SystemPrompt: "sys", Task: "task"})
+	assert.NoError(t, err)
+	assert.Equal(t, "all good", content)
+}
+
+func TestClaudeProviderStreaming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "event: content_block_delta\n")
+		fmt.Fprint(w, "data: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"a\"}}\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"b\"}}\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"message_stop\"}\n\n")
+	}))
+	defer server.Close()
+
+	provider := newClaudeProvider(Config{}, server.Client()).(*claudeProvider)
+	provider.endpoint = server.URL
+
+	content, err := provider.Generate(context.Background(), GenerateRequest{Stream: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "ab", content)
+}
+
+func TestOpenAICompatibleBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"local review"}}]}`)
+	}))
+	defer server.Close()
+
+	provider := newOpenAICompatibleProvider(Config{Model: "llama3", BaseURL: server.URL + "/v1/"}, server.Client())
+	content, err := provider.Generate(context.Background(), GenerateRequest{Task: "Review"})
+	assert.NoError(t, err)
+	assert.Equal(t, "local review", content)
+}
+
+func TestOpenAICompatibleRequiresBaseURL(t *testing.T) {
+	provider := newOpenAICompatibleProvider(Config{Model: "llama3"}, http.DefaultClient)
+
+	_, err := provider.Generate(context.Background(), GenerateRequest{})
+	assert.ErrorContains(t, err, "requires llm.base_url")
+}
+
+func TestBaseURLOverridesDefaults(t *testing.T) {
+	config := Config{BaseURL: "https://proxy.internal/v1"}
+
+	assert.Equal(t, "https://proxy.internal/v1/chat/completions", newOpenAIProvider(config, nil).(*openAIProvider).endpoint)
+	assert.Equal(t, "https://proxy.internal/v1/messages", newClaudeProvider(config, nil).(*claudeProvider).endpoint)
+	assert.Equal(t, "http://localhost:11434/v1/chat/completions", newOllamaProvider(Config{}, nil).(*openAIProvider).endpoint)
+}
+
+func TestGenerateCancelledContext(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release // Hang until the test is over
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := New(Config{Provider: "openai-compatible", Model: "llama3", BaseURL: server.URL})
+	ctx, cancel := context.WithCancel(context.Background())
+	respChan := client.Generate(ctx, GenerateRequest{Task: "Review", Stream: true})
+
+	<-started
+	cancel()
+
+	resp := <-respChan
+	assert.ErrorIs(t, resp.Error, context.Canceled)
+	assert.Empty(t, resp.Content)
+}
+
+func TestOpenAIProviderJSONMode(t *testing.T) {
+	schema := map[string]interface{}{"type": "object"}
+
+	for _, tc := range []struct {
+		name       string
+		jsonSchema bool
+		wantType   string
+	}{
+		{"schema", true, "json_schema"},
+		{"object", false, "json_object"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var payload openAIRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+				assert.Equal(t, tc.wantType, payload.ResponseFormat.Type)
+				if tc.jsonSchema {
+					assert.Equal(t, "response", payload.ResponseFormat.JSONSchema.Name)
+					assert.Equal(t, schema, payload.ResponseFormat.JSONSchema.Schema)
+				}
+				fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"{\"findings\":[]}"}}]}`)
+			}))
+			defer server.Close()
+
+			provider := newOpenAIProvider(Config{Model: "gpt-4o"}, server.Client()).(*openAIProvider)
+			provider.endpoint = server.URL
+			provider.jsonSchema = tc.jsonSchema
+
+			content, err := provider.Generate(context.Background(), GenerateRequest{Task: "Review", JSONSchema: schema})
+			assert.NoError(t, err)
+			assert.Equal(t, `{"findings":[]}`, content)
+		})
+	}
+}
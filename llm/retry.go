@@ -0,0 +1,199 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/revrost/glimpse/styles"
+)
+
+const (
+	retryBaseDelay = 1 * time.Second
+	retryMaxDelay  = 30 * time.Second
+	// Servers asking us to wait longer than this are treated as hard failures
+	maxRetryAfter = 2 * time.Minute
+)
+
+// RetryFunc is called before each retry with the attempt number (1-based),
+// the delay before the next attempt and the reason for retrying
+type RetryFunc func(attempt int, wait time.Duration, reason string)
+
+// retryTransport retries requests that fail with a rate limit, a server error or
+// a network error, using jittered exponential backoff and honouring Retry-After
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	onRetry    RetryFunc
+}
+
+// newRetryTransport wraps base with the retry policy from config
+func newRetryTransport(base http.RoundTripper, config Config) *retryTransport {
+	onRetry := config.OnRetry
+	if onRetry == nil {
+		onRetry = printRetry
+	}
+	return &retryTransport{
+		base:       base,
+		maxRetries: config.MaxRetries,
+		baseDelay:  retryBaseDelay,
+		maxDelay:   retryMaxDelay,
+		onRetry:    onRetry,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.GetBody == nil {
+		return t.base.RoundTrip(req) // Body cannot be replayed
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt >= t.maxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		wait, reason, retry := t.shouldRetry(resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		t.onRetry(attempt+1, wait, reason)
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry decides whether a response is worth retrying and for how long to wait
+func (t *retryTransport) shouldRetry(resp *http.Response, err error, attempt int) (time.Duration, string, bool) {
+	if err != nil {
+		return t.backoff(attempt), "connection failed", true
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		if isQuotaExhausted(resp) {
+			return 0, "", false
+		}
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"))
+		if !ok {
+			wait = t.backoff(attempt)
+		}
+		if wait > maxRetryAfter {
+			return 0, "", false
+		}
+		return wait, "rate limited", true
+
+	case resp.StatusCode >= 500:
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"))
+		if !ok || wait > maxRetryAfter {
+			wait = t.backoff(attempt)
+		}
+		return wait, fmt.Sprintf("server error (%d)", resp.StatusCode), true
+	}
+
+	return 0, "", false
+}
+
+// backoff returns an exponentially growing delay with equal jitter
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.baseDelay << attempt
+	if delay <= 0 || delay > t.maxDelay {
+		delay = t.maxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// isQuotaExhausted peeks at a 429 body to tell an exhausted quota (not worth
+// retrying) from a transient rate limit. The body is restored for the caller.
+func isQuotaExhausted(resp *http.Response) bool {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return isQuotaMessage(string(body))
+}
+
+// isQuotaMessage reports whether an error message describes an exhausted quota or billing problem
+func isQuotaMessage(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "insufficient_quota") ||
+		strings.Contains(message, "exceeded your current quota") ||
+		strings.Contains(message, "billing")
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until ctx is cancelled
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// printRetry is the default RetryFunc, printing a notice to stderr
+func printRetry(attempt int, wait time.Duration, reason string) {
+	fmt.Fprintf(os.Stderr, "\r%s\n", styles.CreateWarningStyle(
+		fmt.Sprintf("%s, retrying in %s (attempt %d)", reason, formatWait(wait), attempt),
+	))
+}
+
+// formatWait renders a retry delay for humans, e.g. "4s" or "500ms"
+func formatWait(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
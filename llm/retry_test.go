@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newRetryTestClient returns an openai-compatible client against server with fast backoff
+func newRetryTestClient(server *httptest.Server, maxRetries int, retries *[]string) *Client {
+	client := New(Config{
+		Provider:   "openai-compatible",
+		Model:      "llama3",
+		BaseURL:    server.URL,
+		MaxRetries: maxRetries,
+		OnRetry: func(attempt int, wait time.Duration, reason string) {
+			*retries = append(*retries, fmt.Sprintf("%s, retrying in %s", reason, formatWait(wait)))
+		},
+	})
+	transport := client.client.Transport.(*retryTransport)
+	transport.baseDelay = time.Millisecond
+	transport.maxDelay = 5 * time.Millisecond
+	return client
+}
+
+func TestRetryOnRateLimit(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"error":{"message":"slow down"}}`, http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	}))
+	defer server.Close()
+
+	var retries []string
+	client := newRetryTestClient(server, 3, &retries)
+	resp := <-client.Generate(context.Background(), GenerateRequest{Task: "Review", Stream: true})
+
+	assert.NoError(t, resp.Error)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, []string{"rate limited, retrying in 0s"}, retries)
+}
+
+func TestRetryGivesUpOnServerErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "<html>internal error</html>", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var retries []string
+	provider := newOpenAICompatibleProvider(Config{Model: "llama3", BaseURL: server.URL}, newRetryTestClient(server, 2, &retries).client)
+
+	_, err := provider.Generate(context.Background(), GenerateRequest{})
+	assert.ErrorIs(t, err, ErrServer)
+	assert.ErrorContains(t, err, "internal error")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Len(t, retries, 2)
+}
+
+func TestNoRetryOnAuthOrQuota(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{"auth", http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided"}}`, ErrAuth},
+		{"quota", http.StatusTooManyRequests, `{"error":{"type":"insufficient_quota"}}`, ErrQuota},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer server.Close()
+
+			var retries []string
+			provider := newOpenAICompatibleProvider(Config{Model: "llama3", BaseURL: server.URL}, newRetryTestClient(server, 3, &retries).client)
+
+			_, err := provider.Generate(context.Background(), GenerateRequest{})
+			assert.ErrorIs(t, err, tc.expected)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+			assert.Empty(t, retries)
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	wait, ok := retryAfter("4")
+	assert.True(t, ok)
+	assert.Equal(t, 4*time.Second, wait)
+
+	wait, ok = retryAfter(time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, 10*time.Second, wait, float64(2*time.Second))
+
+	_, ok = retryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestBackoffIsBounded(t *testing.T) {
+	transport := &retryTransport{baseDelay: time.Second, maxDelay: 30 * time.Second}
+
+	for attempt := 0; attempt < 70; attempt++ {
+		wait := transport.backoff(attempt)
+		assert.Greater(t, wait, time.Duration(0))
+		assert.LessOrEqual(t, wait, 30*time.Second)
+	}
+	assert.GreaterOrEqual(t, transport.backoff(2), 2*time.Second)
+}
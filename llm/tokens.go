@@ -0,0 +1,42 @@
+package llm
+
+import "math"
+
+// defaultCharsPerToken is used for providers without a known ratio
+const defaultCharsPerToken = 3.5
+
+// charsPerToken approximates each provider's tokenizer on source code and diffs.
+// The ratios err on the low side so estimates over-count rather than overflow.
+var charsPerToken = map[string]float64{
+	"openai":            4.0,
+	"openai-compatible": 3.5,
+	"ollama":            3.5,
+	"claude":            3.5,
+	"gemini":            4.0,
+	"zai":               3.5,
+}
+
+// TokenEstimator is implemented by providers that can count tokens more
+// accurately than the character heuristic
+type TokenEstimator interface {
+	EstimateTokens(text string) int
+}
+
+// EstimateTokens approximates the number of tokens text uses with the named provider
+func EstimateTokens(provider string, text string) int {
+	ratio, ok := charsPerToken[provider]
+	if !ok {
+		ratio = defaultCharsPerToken
+	}
+	return int(math.Ceil(float64(len(text)) / ratio))
+}
+
+// EstimateTokens approximates the number of tokens text uses with the client's provider
+func (c *Client) EstimateTokens(text string) int {
+	if provider, err := c.resolveProvider(); err == nil {
+		if estimator, ok := provider.(TokenEstimator); ok {
+			return estimator.EstimateTokens(text)
+		}
+	}
+	return EstimateTokens(c.config.Provider, text)
+}
@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	text := strings.Repeat("a", 100)
+
+	assert.Equal(t, 25, EstimateTokens("openai", text))
+	assert.Equal(t, 29, EstimateTokens("claude", text))
+	assert.Equal(t, 29, EstimateTokens("unknown", text))
+	assert.Equal(t, 0, EstimateTokens("openai", ""))
+}
+
+// countingProvider counts one token per word
+type countingProvider struct {
+	Fake
+}
+
+func (p *countingProvider) EstimateTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+func TestClientEstimateTokens(t *testing.T) {
+	client := New(Config{Provider: "openai"})
+	assert.Equal(t, 3, client.EstimateTokens("0123456789"))
+
+	Register("counting-test", func(Config, *http.Client) Provider { return &countingProvider{} })
+	client = New(Config{Provider: "counting-test"})
+	assert.Equal(t, 2, client.EstimateTokens("two words"))
+}
@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -29,7 +30,7 @@ func TestGenerateZAI(t *testing.T) {
 		Task:         "Review this Go function for potential issues.",
 	}
 
-	respChan := client.Generate(req)
+	respChan := client.Generate(context.Background(), req)
 	resp := <-respChan
 
 	assert.NoError(t, resp.Error)
@@ -57,7 +58,7 @@ func TestZAIModelDefault(t *testing.T) {
 		Task:         "Say hello",
 	}
 
-	respChan := client.Generate(req)
+	respChan := client.Generate(context.Background(), req)
 	resp := <-respChan
 
 	assert.NoError(t, resp.Error)
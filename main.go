@@ -1,18 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/revrost/glimpse/cache"
 	"github.com/revrost/glimpse/config"
 	"github.com/revrost/glimpse/git"
 	"github.com/revrost/glimpse/llm"
 	"github.com/revrost/glimpse/logs"
+	"github.com/revrost/glimpse/match"
+	"github.com/revrost/glimpse/redact"
+	"github.com/revrost/glimpse/review"
 	"github.com/revrost/glimpse/styles"
 	"github.com/revrost/glimpse/ui"
 	"github.com/revrost/glimpse/watcher"
@@ -27,16 +36,54 @@ var (
 const (
 	maxBatchSize      = 100
 	idleTimerDuration = time.Hour
+	stagedReviewTitle = "STAGED CHANGE REVIEW"
+)
+
+// Exit codes of one-shot reviews, distinct so CI can tell them apart
+const (
+	exitOK        = 0
+	exitError     = 1 // Configuration, git or report errors
+	exitUsage     = 2
+	exitFindings  = 3 // Findings at or above --fail-on
+	exitProvider  = 4 // The LLM provider failed or its answer could not be parsed
+	exitNoChanges = 5 // Nothing to review (CI mode only)
+	exitCancelled = 130
 )
 
 func main() {
+	ui.ConfigureOutput()
+
+	// Subcommands are dispatched before the global flags are parsed
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "hook":
+			os.Exit(runHookCommand(os.Args[2:]))
+		case "review":
+			os.Exit(runReviewCommand(os.Args[2:]))
+		case "history":
+			os.Exit(runHistoryCommand(os.Args[2:]))
+		case "fix":
+			os.Exit(runFixCommand(os.Args[2:]))
+		}
+	}
+
 	showVersion := flag.Bool("version", false, "Show version information")
 	headless := flag.Bool("hh", false, "Headless mode: run once, review git changes, and exit")
-	fixMode := flag.Bool("f", false, "Fix mode: automatically run crush to fix issues identified by review")
-	streamMode := flag.Bool("s", false, "Stream mode: show LLM reasoning and response in real-time")
+	fixMode := flag.Bool("f", false, "Fix mode: automatically run the fix agent (fix.agent, default crush) to fix issues identified by review")
+	yes := flag.Bool("yes", false, "Apply native fix patches and agent fixes without asking for confirmation")
+	streamMode := flag.Bool("s", false, "Stream mode: show LLM reasoning and findings in real-time")
 	var provider string
 	flag.StringVar(&provider, "provider", "", "LLM provider and model in format 'provider:model' (e.g., 'zai:glm-4.6')")
 	flag.StringVar(&provider, "p", "", "Alias for --provider: LLM provider and model in format 'provider:model' (e.g., 'zai:glm-4.6')")
+	baseURL := flag.String("base-url", "", "Override the LLM API base URL (e.g., 'http://localhost:11434/v1' for openai-compatible servers)")
+	minSeverity := flag.String("min-severity", "", "Only report findings at or above this severity (critical, high, medium, low, info)")
+	mode := flag.String("mode", modeStage, "What triggers a review: 'save' (files matching watch patterns), 'stage' (the git index) or 'both'")
+	noCache := flag.Bool("no-cache", false, "Review every file again instead of reusing cached findings")
+	noIncremental := flag.Bool("no-incremental", false, "Send every staged hunk instead of only those changed since the previous staged review")
+	format, outputPath := addOutputFlags(flag.CommandLine)
+	ciMode := flag.Bool("ci", false, "CI mode: headless, plain output, fail on findings at or above hook.fail_on and exit 5 when there is nothing to review")
+	failOn := flag.String("fail-on", "", "Exit 3 when findings reach this severity (critical, high, medium, low, info)")
+	base := flag.String("base", "", "Review the commits since the merge base with this ref instead of the working tree (e.g. 'origin/main')")
 	flag.Parse()
 
 	if *showVersion {
@@ -49,9 +96,25 @@ func main() {
 	}
 
 	// Headless mode: run once and exit
-	if *headless {
-		runHeadlessMode(provider, *fixMode, *streamMode)
-		return
+	if *headless || *ciMode {
+		out, err := parseOutput(*format, *outputPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+			os.Exit(exitUsage)
+		}
+		os.Exit(runHeadlessMode(headlessOptions{
+			provider:    provider,
+			baseURL:     *baseURL,
+			minSeverity: *minSeverity,
+			failOn:      *failOn,
+			base:        *base,
+			ci:          *ciMode,
+			fix:         *fixMode,
+			yes:         *yes,
+			stream:      *streamMode,
+			noCache:     *noCache,
+			out:         out,
+		}))
 	}
 
 	fmt.Println(styles.CreateHeader("Glimpse: AI-Powered Micro-Reviewer"))
@@ -79,100 +142,171 @@ func main() {
 	}
 
 	// Override provider and model if specified via CLI
-	if provider != "" {
-		parts := strings.SplitN(provider, ":", 2)
-		if len(parts) != 2 {
-			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle("Invalid provider format. Expected 'provider:model'"))
-			os.Exit(1)
-		}
-		cfg.LLM.Provider = parts[0]
-		cfg.LLM.Model = parts[1]
+	if err := applyProviderOverride(cfg, provider, *baseURL); err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		os.Exit(1)
+	}
+	if err := applyReviewOverride(cfg, *minSeverity); err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		os.Exit(1)
+	}
+	if *noCache {
+		cfg.Cache.Enabled = false
+	}
+	if *noIncremental {
+		cfg.Review.Incremental = false
+	}
+	fixer, err := newFixer(cfg, *fixMode, *yes)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		os.Exit(1)
 	}
 
-	llmClient := llm.New(llm.Config{
-		Provider:     cfg.LLM.Provider,
-		Model:        cfg.LLM.Model,
-		APIKey:       cfg.LLM.APIKey,
-		SystemPrompt: cfg.LLM.SystemPrompt,
-	})
+	reviewer, err := newReviewer(cfg, newLLMClient(cfg))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		os.Exit(1)
+	}
 
 	logTailer := logs.New(logs.Config{
 		File:  cfg.Logs.File,
 		Lines: cfg.Logs.Lines,
 	})
 
-	fileWatcher, err := watcher.New(watcher.Config{
-		Watch:    cfg.Watch,
-		Ignore:   cfg.Ignore,
-		Debounce: cfg.GetDebounceDuration(),
-	})
+	watchSave, watchStage, err := parseMode(*mode)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
-		os.Exit(1)
+		os.Exit(2)
 	}
-	defer fileWatcher.Close()
 
 	done := make(chan struct{})
-	batchChan := make(chan []watcher.FileEvent, 5)
-
-	startBatcher(
-		fileWatcher.Events(),
-		batchChan,
-		cfg.GetDebounceDuration(),
-		done,
-	)
+	var batchChan chan []watcher.FileEvent
+	if watchSave {
+		fileWatcher, err := newFileWatcher(cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+			os.Exit(1)
+		}
+		defer fileWatcher.Close()
+
+		batchChan = make(chan []watcher.FileEvent, 5)
+		startBatcher(
+			fileWatcher.Events(),
+			batchChan,
+			cfg.GetDebounceDuration(),
+			done,
+		)
 
-	fileWatcher.Start()
+		fileWatcher.Start()
 
-	fmt.Println(
-		styles.Status.Render(
-			fmt.Sprintf("Watching %d patterns: %v", len(cfg.Watch), cfg.Watch),
-		),
-	)
+		fmt.Println(
+			styles.Status.Render(
+				fmt.Sprintf("Watching %d patterns: %v", len(cfg.Watch), cfg.Watch),
+			),
+		)
+	}
+	if watchStage {
+		fmt.Println(styles.Status.Render("Watching staged changes"))
+	}
 	fmt.Println(
 		styles.Info.Render(
 			fmt.Sprintf("Using LLM: %s (%s)", strings.ToUpper(cfg.LLM.Provider), cfg.LLM.Model),
 		),
 	)
-	if *fixMode {
+	if fixer != nil {
 		fmt.Println(
 			styles.Status.Render(
-				"Fix mode: ON - Crush will auto-fix issues",
+				fmt.Sprintf("Fix mode: ON - %s will auto-fix issues", fixer.name),
 			),
 		)
 	}
 	fmt.Println(styles.Muted.Render("Press Ctrl+C to exit"))
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Ctrl+C cancels ctx, which aborts any in-flight LLM request
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	var lastStagedHash string
-	gitTicker := time.NewTicker(1 * time.Second)
-	defer gitTicker.Stop()
+	var reviewDone <-chan struct{}
+	cancelReview := context.CancelFunc(func() {})
+
+	// nextReviewContext cancels the running review, which a newer change makes
+	// stale, and returns the context for the next one
+	nextReviewContext := func() (context.Context, bool) {
+		if isRunning(reviewDone) {
+			fmt.Println(styles.Muted.Render("Changes updated, cancelling stale review..."))
+		}
+		cancelReview()
+		if !waitForReview(ctx, reviewDone) {
+			return nil, false
+		}
+		var reviewCtx context.Context
+		reviewCtx, cancelReview = context.WithCancel(ctx)
+		return reviewCtx, true
+	}
+
+	// Review whenever the staged content changes; the first check reviews what is already staged
+	checkStaged := func() {
+		hash, err := git.StagedHash()
+		if err != nil || hash == lastStagedHash {
+			return
+		}
+		if fixer.busy() {
+			// Staged by the fixer, which reviews its changes when it finishes
+			lastStagedHash = hash
+			return
+		}
+		files, err := git.StagedFiles()
+		if err != nil {
+			return
+		}
+		state := &git.StagedState{StagedFiles: files, Hash: hash}
+		lastStagedHash = hash
+
+		reviewCtx, ok := nextReviewContext()
+		if !ok {
+			return
+		}
+		reviewDone = processStagedChange(reviewCtx, state, cfg, reviewer, logTailer, fixer, *streamMode)
+		if reviewDone != nil {
+			fmt.Println(styles.Info.Render("Git state changed, reviewing..."))
+		} else {
+			fmt.Println(styles.Muted.Render("Git state changed, not reviewing (no changes)."))
+		}
+	}
+
+	var indexChanges <-chan struct{}
+	var pollTicks <-chan time.Time
+	if watchStage {
+		var closeIndexWatch func()
+		indexChanges, pollTicks, closeIndexWatch = watchIndex()
+		defer closeIndexWatch()
+		checkStaged()
+	}
 
 	for {
 		select {
-		// -- Disabled file watching for now
-		// case batch := <-batchChan:
-		// fmt.Println(styles.CreateBatchHeader(len(batch)))
-		// fmt.Println(batch)
-		// processBatch(batch, cfg, llmClient, logTailer)
-
-		case <-gitTicker.C:
-			state, err := git.GetStagedState()
-			if err == nil && state.Hash != lastStagedHash {
-				lastStagedHash = state.Hash
-				// fmt.Println(styles.CreateBatchHeader(len(batch)))
-				isReviewing := processStagedChange(state, cfg, llmClient, logTailer, *fixMode, *streamMode)
-				if isReviewing {
-					fmt.Println(styles.Info.Render("Git state changed, reviewing..."))
-				} else {
-					fmt.Println(styles.Muted.Render("Git state changed, not reviewing (no changes)."))
-				}
+		case batch := <-batchChan:
+			if fixer.busy() {
+				continue // Edits made by the fixer
 			}
+			reviewCtx, ok := nextReviewContext()
+			if !ok {
+				continue
+			}
+			fmt.Println(styles.CreateBatchHeader(len(batch)))
+			reviewDone = processBatch(reviewCtx, batch, cfg, reviewer, logTailer, fixer, *streamMode)
+
+		case <-indexChanges:
+			checkStaged()
+
+		case <-pollTicks:
+			checkStaged()
 
-		case <-sigChan:
+		case <-ctx.Done():
 			fmt.Println(styles.CreateWarningStyle("\nShutting down Glimpse..."))
+			cancelReview()
+			waitForReview(context.Background(), reviewDone)
 			close(done)
 			return
 		}
@@ -181,25 +315,279 @@ func main() {
 
 /* ----------------------- Helpers ----------------------- */
 
-func isIgnoredFile(file string, cfg *config.Config) bool {
-	// Always ignore .git directory changes to prevent infinite loops
-	// caused by your own git polling.
-	if strings.Contains(file, "/.git/") || strings.HasPrefix(file, ".git/") {
-		return true
+// Review trigger modes for --mode
+const (
+	modeSave  = "save"
+	modeStage = "stage"
+	modeBoth  = "both"
+)
+
+// parseMode reports which triggers a --mode value enables
+func parseMode(mode string) (save bool, stage bool, err error) {
+	switch mode {
+	case modeSave:
+		return true, false, nil
+	case modeStage:
+		return false, true, nil
+	case modeBoth:
+		return true, true, nil
+	default:
+		return false, false, fmt.Errorf("Invalid mode %q (expected %s, %s or %s)", mode, modeSave, modeStage, modeBoth)
 	}
+}
 
-	// 2. Ignore the application's own log file if it's inside the repo
-	if strings.Contains(file, cfg.Logs.File) {
-		return true
+// newFileWatcher watches the configured patterns, skipping files that are not reviewed
+func newFileWatcher(cfg *config.Config) (*watcher.Watcher, error) {
+	matcher, err := loadMatcher(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	// 3. Check user config
-	for _, pattern := range cfg.Ignore {
-		if strings.Contains(file, pattern) {
-			return true
+	return watcher.New(watcher.Config{
+		Watch:    cfg.Watch,
+		Ignore:   cfg.Ignore,
+		Debounce: cfg.GetDebounceDuration(),
+		Matcher:  matcher,
+	})
+}
+
+// watchIndex watches the git index, HEAD and refs for changes. When fsnotify
+// is unavailable it falls back to polling every second. Exactly one of the
+// returned channels is non-nil.
+func watchIndex() (<-chan struct{}, <-chan time.Time, func()) {
+	paths, err := git.ResolveRepoPaths()
+	if err == nil {
+		var indexWatcher *watcher.IndexWatcher
+		indexWatcher, err = watcher.NewIndexWatcher(watcher.IndexConfig{
+			Files: []string{paths.Index, paths.Head, paths.PackedRefs},
+			Dirs:  []string{paths.Refs},
+		})
+		if err == nil {
+			return indexWatcher.Changes(), nil, func() { indexWatcher.Close() }
 		}
 	}
-	return false
+
+	fmt.Fprintln(os.Stderr, styles.CreateWarningStyle(fmt.Sprintf("Cannot watch the git index (%v), polling instead", err)))
+	ticker := time.NewTicker(1 * time.Second)
+	return nil, ticker.C, ticker.Stop
+}
+
+// matchers holds the matcher of the running configuration, so the working
+// tree is walked once rather than for every review
+var matchers struct {
+	mu      sync.Mutex
+	cfg     *config.Config
+	root    string
+	matcher *match.Matcher
+}
+
+// loadMatcher returns the matcher for cfg, building it again only when it is
+// stale for the given paths, e.g. since an ignore or attribute file changed
+func loadMatcher(cfg *config.Config, paths ...string) (*match.Matcher, error) {
+	root, err := git.Root()
+	if err != nil {
+		root = "."
+	}
+
+	matchers.mu.Lock()
+	defer matchers.mu.Unlock()
+	if matchers.matcher != nil && matchers.cfg == cfg && matchers.root == root && !matchers.matcher.Stale(paths...) {
+		return matchers.matcher, nil
+	}
+	matcher, err := newMatcher(cfg, root)
+	if err != nil {
+		return nil, err
+	}
+	matchers.cfg, matchers.root, matchers.matcher = cfg, root, matcher
+	return matcher, nil
+}
+
+// newMatcher builds the matcher deciding which files below root are watched and reviewed
+func newMatcher(cfg *config.Config, root string) (*match.Matcher, error) {
+
+	ignore := append([]string{}, cfg.Ignore...)
+	if cfg.Logs.File != "" {
+		ignore = append(ignore, cfg.Logs.File) // The application's own log file
+	}
+	opts := match.Options{Root: root, Ignore: ignore, Defaults: cfg.Review.SkipGenerated}
+	if paths, err := git.ResolveRepoPaths(); err == nil {
+		opts.Exclude, opts.Attributes = paths.InfoExclude, paths.InfoAttributes
+	}
+	matcher, err := match.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore files: %w", err)
+	}
+	return matcher, nil
+}
+
+// reviewableDiffs drops the diffs of files that are not reviewed
+func reviewableDiffs(cfg *config.Config, diffs []git.Diff) ([]git.Diff, error) {
+	paths := make([]string, len(diffs))
+	for i, d := range diffs {
+		paths[i] = d.FilePath
+	}
+	matcher, err := loadMatcher(cfg, paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []git.Diff
+	for _, d := range diffs {
+		if !matcher.Skip(d.FilePath) {
+			kept = append(kept, d)
+		}
+	}
+	return kept, nil
+}
+
+// applyProviderOverride applies the 'provider:model' and base URL CLI flags to cfg
+func applyProviderOverride(cfg *config.Config, provider string, baseURL string) error {
+	if provider != "" {
+		parts := strings.SplitN(provider, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("Invalid provider format. Expected 'provider:model'")
+		}
+		if _, ok := llm.Lookup(parts[0]); !ok {
+			return fmt.Errorf("Unknown provider %q. Available providers: %s", parts[0], strings.Join(llm.Providers(), ", "))
+		}
+
+		// A configured api_key and base_url belong to the configured provider
+		if parts[0] != cfg.LLM.Provider {
+			cfg.LLM.APIKey = config.APIKeyFromEnv(parts[0])
+			cfg.LLM.BaseURL = ""
+		}
+		cfg.LLM.Provider = parts[0]
+		cfg.LLM.Model = parts[1]
+	}
+
+	if baseURL != "" {
+		cfg.LLM.BaseURL = baseURL
+	}
+	return nil
+}
+
+// applyReviewOverride applies the --min-severity flag and validates the review config
+func applyReviewOverride(cfg *config.Config, minSeverity string) error {
+	if minSeverity != "" {
+		cfg.Review.MinSeverity = minSeverity
+	}
+	if cfg.Review.MinSeverity == "" {
+		return nil
+	}
+	severity, err := review.ParseSeverity(cfg.Review.MinSeverity)
+	if err != nil {
+		return fmt.Errorf("Invalid min severity: %w", err)
+	}
+	cfg.Review.MinSeverity = string(severity)
+	return nil
+}
+
+// newLLMClient creates an LLM client from the loaded configuration
+func newLLMClient(cfg *config.Config) *llm.Client {
+	return llm.New(llm.Config{
+		Provider:     cfg.LLM.Provider,
+		Model:        cfg.LLM.Model,
+		APIKey:       cfg.LLM.APIKey,
+		SystemPrompt: cfg.LLM.SystemPrompt,
+		BaseURL:      cfg.LLM.BaseURL,
+		MaxRetries:   cfg.LLM.MaxRetries,
+	})
+}
+
+// newReviewer creates a chunking reviewer from the loaded configuration
+func newReviewer(cfg *config.Config, client *llm.Client) (*review.Reviewer, error) {
+	redactor, err := newRedactor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	store, err := openHistory(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var findingsCache review.Cache
+	if store := openCache(cfg); store != nil {
+		findingsCache = store // Keep the interface nil when caching is off
+	}
+
+	return &review.Reviewer{
+		Client:         client,
+		SystemPrompt:   cfg.LLM.SystemPrompt,
+		MaxChunkTokens: cfg.Review.MaxChunkTokens,
+		Concurrency:    cfg.Review.Concurrency,
+		Redactor:       redactor,
+		Cache:          findingsCache,
+		Progress: func(done, total int) {
+			if done == 0 {
+				fmt.Println(styles.Status.Render(fmt.Sprintf("Changes split into %d chunks, reviewing in parallel...", total)))
+				return
+			}
+			fmt.Println(styles.Muted.Render(fmt.Sprintf("Reviewed chunk %d/%d", done, total)))
+		},
+		OnComplete: func(req review.Request, result *review.Result) {
+			recordReview(store, cfg, req, result)
+		},
+	}, nil
+}
+
+// openCache returns the review findings cache, or nil when it is disabled
+func openCache(cfg *config.Config) *cache.Store {
+	if !cfg.Cache.Enabled {
+		return nil
+	}
+	if cfg.Cache.Dir != "" {
+		return cache.Open(cfg.Cache.Dir)
+	}
+	if commonDir, err := git.CommonDir(); err == nil {
+		return cache.Open(filepath.Join(commonDir, "glimpse", "cache"))
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return cache.Open(filepath.Join(dir, "glimpse"))
+	}
+	return nil
+}
+
+// newRedactor creates the secret and PII redactor, or nil when redaction is disabled
+func newRedactor(cfg *config.Config) (*redact.Redactor, error) {
+	if !cfg.Redact.Enabled {
+		return nil, nil
+	}
+
+	patterns := make([]redact.Pattern, 0, len(cfg.Redact.Patterns))
+	for _, p := range cfg.Redact.Patterns {
+		patterns = append(patterns, redact.Pattern{Name: p.Name, Regex: p.Regex, Kind: redact.Kind(p.Kind)})
+	}
+	return redact.New(redact.Config{
+		Patterns:      patterns,
+		Entropy:       cfg.Redact.Entropy,
+		BlockOnSecret: cfg.Redact.BlockOnSecret,
+	})
+}
+
+// isRunning reports whether a review started by launchReviewAsync is still in flight
+func isRunning(reviewDone <-chan struct{}) bool {
+	if reviewDone == nil {
+		return false
+	}
+	select {
+	case <-reviewDone:
+		return false
+	default:
+		return true
+	}
+}
+
+// waitForReview blocks until the review finishes so outputs never interleave.
+// It returns false if ctx is cancelled first.
+func waitForReview(ctx context.Context, reviewDone <-chan struct{}) bool {
+	if reviewDone == nil {
+		return true
+	}
+	select {
+	case <-reviewDone:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
 /* ----------------------- Batching ---------------------- */
@@ -259,21 +647,21 @@ func startBatcher(
 
 /* -------------------- Batch Processing -------------------- */
 
+// processBatch starts an async review of the saved files' working tree changes
+// and returns a channel closed when it completes, or nil when there is nothing to review.
 func processBatch(
+	ctx context.Context,
 	events []watcher.FileEvent,
 	cfg *config.Config,
-	llmClient *llm.Client,
+	reviewer *review.Reviewer,
 	logTailer *logs.Tailer,
-) {
+	fixer *autoFixer,
+	streamMode bool,
+) <-chan struct{} {
+	// The watcher has already skipped files that are not reviewed
 	fileSet := make(map[string]struct{})
 	for _, ev := range events {
-		if !isIgnoredFile(ev.Path, cfg) {
-			fileSet[ev.Path] = struct{}{}
-		}
-	}
-
-	if len(fileSet) == 0 {
-		return
+		fileSet[ev.Path] = struct{}{}
 	}
 
 	var files []string
@@ -283,259 +671,370 @@ func processBatch(
 
 	diffs, err := git.GetDiff(files...)
 	if err != nil || len(diffs) == 0 {
-		return
+		return nil
 	}
 
 	logsText, _ := logTailer.Tail()
 
-	var ctx strings.Builder
-	ctx.WriteString("=== FILE CHANGE REVIEW ===\n")
-	for _, d := range diffs {
-		ctx.WriteString(fmt.Sprintf("File: %s\n%s\n\n", d.FilePath, d.Content))
-	}
-	ctx.WriteString("=== RUNTIME LOGS ===\n")
-	ctx.WriteString(logsText)
-
-	req := llm.GenerateRequest{
-		SystemPrompt: cfg.LLM.SystemPrompt,
-		Context:      ctx.String(),
-		Task:         "Review these changes and flag bugs or risks. Be concise.",
+	req := review.Request{
+		Title:  "FILE CHANGE REVIEW",
+		Diffs:  diffs,
+		Logs:   logsText,
+		Task:   "Review these changes and flag bugs or risks. Be concise.",
+		Stream: streamMode,
 	}
 
 	// fmt.Println(styles.CreateProviderInfo(cfg.LLM.Provider, cfg.LLM.Model))
-	launchLLMAsync(llmClient, req, "AI Analysis Complete", false)
+	return launchReviewAsync(ctx, reviewer, req, "AI Analysis Complete", fixer, reviewFilter(cfg))
 }
 
 /* -------------------- Staged Processing -------------------- */
 
+// processStagedChange starts an async review of the staged files and returns a
+// channel closed when it completes, or nil when there is nothing to review.
 func processStagedChange(
+	ctx context.Context,
 	state *git.StagedState,
 	cfg *config.Config,
-	llmClient *llm.Client,
+	reviewer *review.Reviewer,
 	logTailer *logs.Tailer,
-	fixMode bool,
+	fixer *autoFixer,
 	streamMode bool,
-) bool {
+) <-chan struct{} {
 	if len(state.StagedFiles) == 0 {
-		return false
+		return nil
 	}
 
-	var files []string
-	for _, f := range state.StagedFiles {
-		if !isIgnoredFile(f, cfg) {
-			files = append(files, f)
-		}
+	diffs, err := git.GetStagedDiff(state.StagedFiles...)
+	if err != nil {
+		return nil
 	}
-
-	if len(files) == 0 {
-		return false
+	if diffs, err = reviewableDiffs(cfg, diffs); err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return nil
 	}
-
-	diffs, err := git.GetStagedDiff(files...)
-	if err != nil || len(diffs) == 0 {
-		return false
+	if len(diffs) == 0 {
+		return nil
 	}
 
 	logsText, _ := logTailer.Tail()
 
-	var ctx strings.Builder
-	ctx.WriteString("=== STAGED CHANGE REVIEW ===\n")
-	for _, d := range diffs {
-		ctx.WriteString(fmt.Sprintf("File: %s\n%s\n\n", d.FilePath, d.Content))
+	req := review.Request{
+		Title:      stagedReviewTitle,
+		Diffs:      diffs,
+		Logs:       logsText,
+		Task:       "Review staged changes only. Flag bugs or risks. Be concise.",
+		Stream:     streamMode,
+		StagedHash: state.Hash,
 	}
-	ctx.WriteString("=== RUNTIME LOGS ===\n")
-	ctx.WriteString(logsText)
-
-	// Modify system prompt for fix mode
-	systemPrompt := cfg.LLM.SystemPrompt
-	if fixMode {
-		systemPrompt += "\n\nCRITICAL: Your review MUST start with a header line exactly like this:\nNEED FIX: YES   (if changes are required)\nor\nNEED FIX: NO    (if no changes required)\n\nThen provide your review concisely."
-	}
-
-	req := llm.GenerateRequest{
-		SystemPrompt: systemPrompt,
-		Context:      ctx.String(),
-		Task:         "Review staged changes only. Flag bugs or risks. Be concise.",
-		Stream:       streamMode,
+	if cfg.Review.Incremental {
+		// Only send what changed since the previous staged review
+		req.Baseline = stagedBaseline(cfg)
 	}
 
 	// fmt.Println(styles.CreateProviderInfo(cfg.LLM.Provider, cfg.LLM.Model))
-	launchLLMAsync(llmClient, req, "AI Staged Review Complete", fixMode)
-	return true
+	return launchReviewAsync(ctx, reviewer, req, "AI Staged Review Complete", fixer, reviewFilter(cfg))
 }
 
 /* ---------------------- LLM Runner ---------------------- */
 
-func launchLLMAsync(
-	client *llm.Client,
-	req llm.GenerateRequest,
+// launchReviewAsync runs the review in the background and returns a channel that
+// is closed once the review (and any fix) has finished or been cancelled
+func launchReviewAsync(
+	ctx context.Context,
+	reviewer *review.Reviewer,
+	req review.Request,
 	title string,
-	fixMode bool,
-) {
+	fixer *autoFixer,
+	filter review.Filter,
+) <-chan struct{} {
+	done := make(chan struct{})
+
 	go func() {
+		defer close(done)
+
 		// Show that LLM is processing (only for non-streaming mode)
 		if !req.Stream {
-			fmt.Println(styles.Info.Render("LLM analyzing staged changes..."))
+			fmt.Println(styles.Info.Render("LLM analyzing changes..."))
 		}
 
-		resp := <-client.Generate(req)
-		if resp.Error != nil {
-			fmt.Println(styles.CreateErrorStyle(resp.Error.Error()))
+		result, err := reviewer.Review(ctx, req)
+		if ctx.Err() != nil {
+			// Cancelled: superseded by a newer staged state or shutting down
+			return
+		}
+		if err != nil {
+			fmt.Println(styles.CreateErrorStyle(err.Error()))
 			return
 		}
 
-		// Handle fix mode
-		if fixMode {
-			needFix, review, err := parseFixResponse(resp.Content)
-			if err != nil {
-				fmt.Println(styles.CreateErrorStyle(fmt.Sprintf("Failed to parse fix response: %v", err)))
-				// Fall back to normal output
-				fmt.Println(ui.SuccessBox(title, "Review generated successfully"))
-				fmt.Println(resp.Content)
-				return
-			}
+		if fixer != nil {
+			title += " [Fix Mode]"
+		}
+		findings, ok := reportFindings(result, filter, title)
+		if ok && fixer != nil {
+			fixFindings(ctx, fixer, reviewer, req, result.ID, findings, req.StagedHash != "")
+		}
+	}()
 
-			// Print success box with fix mode indicator
-			fmt.Println(ui.SuccessBox(title+" [Fix Mode]", "Review generated successfully"))
+	return done
+}
 
-			// Print NEED FIX header
-			if needFix {
-				fmt.Println(styles.Status.Render("NEED FIX: YES"))
-			} else {
-				fmt.Println(styles.Status.Render("NEED FIX: NO"))
-			}
+/* ---------------------- Findings ---------------------- */
 
-			// Print review
-			fmt.Println()
-			fmt.Println(review)
-
-			// Run crush if fix is needed
-			if needFix {
-				fmt.Println()
-				if err := runCrushFix(review); err != nil {
-					// Already handled in runCrushFix with error messages
-				} else {
-					fmt.Println(styles.CreateInfoStyle("Fix execution complete."))
-				}
-			} else {
-				fmt.Println()
-				fmt.Println(styles.CreateInfoStyle("No fixes needed."))
-			}
+// reportFindings filters the review findings and prints the report.
+// It returns false when the review produced no usable findings.
+func reportFindings(result *review.Result, filter review.Filter, title string) ([]review.Finding, bool) {
+	if !printReviewNotes(os.Stdout, result) {
+		return nil, false
+	}
+
+	findings := result.Findings
+	findings = filter.Apply(findings)
+
+	if title != "" {
+		fmt.Println(ui.SuccessBox(title, review.Summary(findings)))
+	} else {
+		fmt.Println(styles.Status.Render(review.Summary(findings)))
+	}
+
+	if result.Changes == nil {
+		printMarkdown(review.FormatMarkdown(findings))
+		return findings, true
+	}
+	printSection("New", filter.Apply(result.Changes.New))
+	printSection("Still open", filter.Apply(result.Changes.Open))
+	printSection("Resolved", filter.Apply(result.Changes.Resolved))
+	return findings, true
+}
+
+// printReviewNotes prints what was masked, skipped or reused during a review.
+// It returns false, after printing the raw responses, when no findings could be parsed.
+func printReviewNotes(w io.Writer, result *review.Result) bool {
+	if len(result.Redacted) > 0 {
+		fmt.Fprintln(w, styles.CreateWarningStyle("Masked before sending: "+redact.Summary(result.Redacted)))
+	}
+
+	if len(result.Findings) == 0 && len(result.Unparsed) > 0 {
+		// Fall back to the raw response so the review isn't lost
+		fmt.Fprintln(w, styles.CreateErrorStyle(fmt.Sprintf("Failed to parse review findings: %v", result.Invalid)))
+		fmt.Fprintln(w, strings.Join(result.Unparsed, "\n\n"))
+		return false
+	}
+	if result.Invalid != nil {
+		fmt.Fprintln(w, styles.CreateWarningStyle(fmt.Sprintf("Skipped invalid findings: %v", result.Invalid)))
+	}
+
+	if result.Changes != nil {
+		if result.Files == 0 {
+			fmt.Fprintln(w, styles.Muted.Render("Nothing changed since the last review (no LLM call)"))
 		} else {
-			// Normal mode: just output the review
-			fmt.Println(ui.SuccessBox(title, "Review generated successfully"))
-			fmt.Println(resp.Content)
+			fmt.Fprintln(w, styles.Muted.Render(fmt.Sprintf("Reviewed what changed since the last review in %d file(s)", result.Files)))
 		}
-	}()
+	}
+	if result.Cached > 0 {
+		if result.Cached == result.Files {
+			fmt.Fprintln(w, styles.Muted.Render("Cached result: these changes were reviewed before (no LLM call)"))
+		} else {
+			fmt.Fprintln(w, styles.Muted.Render(fmt.Sprintf("Reused cached findings for %d of %d files", result.Cached, result.Files)))
+		}
+	}
+	return true
+}
+
+// reviewFilter builds the findings filter from the configuration
+func reviewFilter(cfg *config.Config) review.Filter {
+	// Severity was validated by applyReviewOverride
+	return review.Filter{
+		MinSeverity: review.Severity(cfg.Review.MinSeverity),
+		Categories:  cfg.Review.Categories,
+	}
 }
 
 /* --------------------- Headless Mode --------------------- */
 
-func runHeadlessMode(provider string, fixMode bool, streamMode bool) {
-	cfg, err := config.Load()
+// headlessOptions are the flags of a headless (-hh) or CI (--ci) review
+type headlessOptions struct {
+	provider    string
+	baseURL     string
+	minSeverity string
+	failOn      string // Severity that fails the run; defaults to hook.fail_on in CI mode
+	base        string // Review base...HEAD instead of the working tree
+	ci          bool
+	fix         bool
+	yes         bool // Apply and keep fixes without confirmation
+	stream      bool
+	noCache     bool
+	out         output
+}
+
+// runHeadlessMode reviews the working tree, or the commits since --base, once
+// and returns the exit code
+func runHeadlessMode(opts headlessOptions) int {
+	if opts.ci {
+		ui.SetPlain()
+		opts.stream = false
+	}
+
+	cfg, err := loadReviewConfig(opts.provider, opts.baseURL, opts.minSeverity)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
-		os.Exit(1)
+		return exitError
+	}
+	if opts.noCache {
+		cfg.Cache.Enabled = false
+	}
+	gate, err := failOnSeverity(cfg, opts.failOn, opts.ci)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return exitUsage
+	}
+	fixer, err := newFixer(cfg, opts.fix, opts.yes)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return exitError
 	}
 
-	// Override provider and model if specified via CLI
-	if provider != "" {
-		parts := strings.SplitN(provider, ":", 2)
-		if len(parts) != 2 {
-			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle("Invalid provider format. Expected 'provider:model'"))
-			os.Exit(1)
-		}
-		cfg.LLM.Provider = parts[0]
-		cfg.LLM.Model = parts[1]
+	req, err := headlessRequest(cfg, opts.base)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return exitError
 	}
+	req.Stream = opts.stream
 
-	// If no provider is configured and not specified via CLI, exit
-	if cfg.LLM.Provider == "" {
-		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle("No LLM provider configured. Use --provider flag or configure with glimpse -p"))
-		os.Exit(1)
+	if len(req.Diffs) == 0 {
+		fmt.Fprintln(opts.out.status(), styles.CreateInfoStyle("No changes to review"))
+		if err := opts.out.write(cfg, req, nil); err != nil {
+			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+			return exitError
+		}
+		if opts.ci {
+			return exitNoChanges
+		}
+		return exitOK
 	}
 
-	llmClient := llm.New(llm.Config{
-		Provider:     cfg.LLM.Provider,
-		Model:        cfg.LLM.Model,
-		APIKey:       cfg.LLM.APIKey,
-		SystemPrompt: cfg.LLM.SystemPrompt,
-	})
+	return runReviewOnce(cfg, req, fixer, gate, opts.out)
+}
+
+// headlessRequest reviews the working tree against HEAD, or with a base the
+// commits since the merge base with it, which also works on detached CI checkouts
+func headlessRequest(cfg *config.Config, base string) (review.Request, error) {
+	if base != "" {
+		revision, err := git.ResolveRange(base + "...HEAD")
+		if err != nil {
+			return review.Request{}, fmt.Errorf("%w (shallow clone? fetch the base with enough history, e.g. fetch-depth: 0)", err)
+		}
+		return revisionRequest(cfg, revision)
+	}
 
 	// Get all changes (staged and unstaged)
 	diffs, err := git.GetDiff()
+	if err == nil {
+		diffs, err = reviewableDiffs(cfg, diffs)
+	}
 	if err != nil {
-		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
-		os.Exit(1)
+		return review.Request{}, err
 	}
+	return review.Request{
+		Title: "GIT CHANGE REVIEW",
+		Diffs: diffs,
+		Task:  "Review these git changes. Flag bugs, security issues, or potential improvements. Be concise.",
+	}, nil
+}
 
-	if len(diffs) == 0 {
-		fmt.Println(styles.CreateInfoStyle("No changes to review"))
-		return
+// failOnSeverity parses --fail-on. In CI mode it defaults to hook.fail_on;
+// otherwise an empty value means findings never fail the run.
+func failOnSeverity(cfg *config.Config, failOn string, ci bool) (review.Severity, error) {
+	if failOn == "" && ci {
+		failOn = cfg.Hook.FailOn
+	}
+	if failOn == "" {
+		return "", nil
+	}
+	severity, err := review.ParseSeverity(failOn)
+	if err != nil {
+		return "", fmt.Errorf("invalid fail-on severity: %w", err)
 	}
+	return severity, nil
+}
 
-	// Build context for review
-	var ctx strings.Builder
-	ctx.WriteString("=== GIT CHANGE REVIEW ===\n")
-	for _, d := range diffs {
-		ctx.WriteString(fmt.Sprintf("File: %s\n%s\n\n", d.FilePath, d.Content))
+// loadReviewConfig loads the config for a one-shot review and applies the CLI overrides
+func loadReviewConfig(provider string, baseURL string, minSeverity string) (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
 	}
 
-	// Modify system prompt for fix mode
-	systemPrompt := cfg.LLM.SystemPrompt
-	if fixMode {
-		systemPrompt += "\n\nCRITICAL: Your review MUST start with a header line exactly like this:\nNEED FIX: YES   (if changes are required)\nor\nNEED FIX: NO    (if no changes required)\n\nThen provide your review concisely."
+	// Override provider and model if specified via CLI
+	if err := applyProviderOverride(cfg, provider, baseURL); err != nil {
+		return nil, err
+	}
+	if err := applyReviewOverride(cfg, minSeverity); err != nil {
+		return nil, err
+	}
+
+	// If no provider is configured and not specified via CLI, exit
+	if cfg.LLM.Provider == "" {
+		return nil, fmt.Errorf("No LLM provider configured. Use --provider flag or configure with glimpse -p")
 	}
+	return cfg, nil
+}
 
-	req := llm.GenerateRequest{
-		SystemPrompt: systemPrompt,
-		Context:      ctx.String(),
-		Task:         "Review these git changes. Flag bugs, security issues, or potential improvements. Be concise.",
-		Stream:       streamMode,
+// runReviewOnce reviews req synchronously, reports the findings and runs the
+// fixer, if any. Findings at or above gate (if set) fail the run.
+// It returns the process exit code.
+func runReviewOnce(cfg *config.Config, req review.Request, fixer *autoFixer, gate review.Severity, out output) int {
+	reviewer, err := newReviewer(cfg, newLLMClient(cfg))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return exitError
 	}
 
-	// Run LLM synchronously and output directly
-	resp := <-llmClient.Generate(req)
-	if resp.Error != nil {
-		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(resp.Error.Error()))
-		os.Exit(1)
+	// Ctrl+C aborts the in-flight request
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if !out.terminal() {
+		req.Stream = false // Streamed text would corrupt the report on stdout
 	}
 
-	// Handle fix mode
-	if fixMode {
-		needFix, review, err := parseFixResponse(resp.Content)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Failed to parse fix response: %v", err)))
-			// Fall back to normal output
-			fmt.Println(resp.Content)
-			return
+	// Run the review synchronously and output directly
+	result, err := reviewer.Review(ctx, req)
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateWarningStyle("Review cancelled"))
+		return exitCancelled
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		if errors.Is(err, redact.ErrSecretDetected) {
+			return exitError
 		}
+		return exitProvider
+	}
 
-		// Print NEED FIX header
-		if needFix {
-			fmt.Println(styles.Status.Render("NEED FIX: YES"))
-		} else {
-			fmt.Println(styles.Status.Render("NEED FIX: NO"))
+	findings, ok := out.emit(cfg, req, result, reviewFilter(cfg))
+	if !ok {
+		if len(result.Unparsed) == 0 {
+			return exitError // The report could not be written
 		}
+		if gate != "" || out.writesReport() {
+			return exitProvider // No verdict to gate on or report
+		}
+		return exitOK
+	}
+	if fixer != nil {
+		fixFindings(ctx, fixer, reviewer, req, result.ID, findings, false)
+	}
 
-		// Print review
-		fmt.Println()
-		fmt.Println(review)
-
-		// Run crush if fix is needed
-		if needFix {
-			fmt.Println()
-			if err := runCrushFix(review); err != nil {
-				// Already handled in runCrushFix with error messages
-			} else {
-				fmt.Println(styles.CreateInfoStyle("Fix execution complete."))
-			}
-		} else {
-			fmt.Println()
-			fmt.Println(styles.CreateInfoStyle("No fixes needed. Review complete."))
+	if gate != "" {
+		blocking := review.Filter{MinSeverity: gate, Categories: cfg.Review.Categories}.Apply(result.Findings)
+		if len(blocking) > 0 {
+			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf(
+				"%d finding(s) at or above %s", len(blocking), gate,
+			)))
+			return exitFindings
 		}
-	} else {
-		// Normal mode: just output the review
-		fmt.Println(resp.Content)
 	}
+	return exitOK
 }
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/revrost/glimpse/config"
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/llm"
+	"github.com/revrost/glimpse/review"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyProviderOverride(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "claude-key")
+
+	cfg := &config.Config{LLM: config.LLMConfig{Provider: "openai", Model: "gpt-4o", APIKey: "openai-key"}}
+	err := applyProviderOverride(cfg, "claude:claude-3-opus-20240229", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "claude", cfg.LLM.Provider)
+	assert.Equal(t, "claude-3-opus-20240229", cfg.LLM.Model)
+	assert.Equal(t, "claude-key", cfg.LLM.APIKey)
+}
+
+func TestApplyProviderOverride_OpenAICompatible(t *testing.T) {
+	cfg := &config.Config{}
+	err := applyProviderOverride(cfg, "openai-compatible:qwen2.5-coder:7b", "http://localhost:8000/v1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "openai-compatible", cfg.LLM.Provider)
+	assert.Equal(t, "qwen2.5-coder:7b", cfg.LLM.Model)
+	assert.Equal(t, "http://localhost:8000/v1", cfg.LLM.BaseURL)
+}
+
+func TestApplyProviderOverride_Invalid(t *testing.T) {
+	cfg := &config.Config{}
+
+	assert.ErrorContains(t, applyProviderOverride(cfg, "openai", ""), "Expected 'provider:model'")
+	assert.ErrorContains(t, applyProviderOverride(cfg, "nope:model", ""), "Unknown provider")
+}
+
+func TestWaitForReview(t *testing.T) {
+	assert.True(t, waitForReview(context.Background(), nil))
+	assert.False(t, isRunning(nil))
+
+	reviewDone := make(chan struct{})
+	assert.True(t, isRunning(reviewDone))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, waitForReview(ctx, reviewDone))
+
+	close(reviewDone)
+	assert.False(t, isRunning(reviewDone))
+	assert.True(t, waitForReview(context.Background(), reviewDone))
+}
+
+func TestLaunchReviewAsyncCancelled(t *testing.T) {
+	fake := &llm.Fake{Content: "should never be printed"}
+	client := llm.NewWithProvider(llm.Config{}, fake)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reviewer := &review.Reviewer{Client: client}
+	req := review.Request{Diffs: []git.Diff{{FilePath: "main.go", Content: "+x"}}, Stream: true}
+
+	done := launchReviewAsync(ctx, reviewer, req, "Review", nil, review.Filter{})
+	<-done
+	assert.Empty(t, fake.Requests())
+}
+
+func TestApplyReviewOverride(t *testing.T) {
+	cfg := &config.Config{Review: config.ReviewConfig{MinSeverity: "low"}}
+
+	assert.NoError(t, applyReviewOverride(cfg, "Major"))
+	assert.Equal(t, "high", cfg.Review.MinSeverity)
+
+	assert.ErrorContains(t, applyReviewOverride(cfg, "urgent"), "Invalid min severity")
+}
+
+func TestResolveRevisionRequiresOneSelector(t *testing.T) {
+	_, err := resolveRevision("", "", 0)
+	assert.ErrorContains(t, err, "exactly one")
+
+	_, err = resolveRevision("main...HEAD", "abc123", 0)
+	assert.ErrorContains(t, err, "exactly one")
+}
+
+func TestParseMode(t *testing.T) {
+	save, stage, err := parseMode("both")
+	assert.NoError(t, err)
+	assert.True(t, save)
+	assert.True(t, stage)
+
+	save, stage, err = parseMode("save")
+	assert.NoError(t, err)
+	assert.True(t, save)
+	assert.False(t, stage)
+
+	_, _, err = parseMode("commit")
+	assert.ErrorContains(t, err, "Invalid mode")
+}
+
+func TestReviewableDiffs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := &config.Config{Ignore: []string{"*_test.go"}, Logs: config.LogsConfig{File: "./tmp/server.log"}}
+	cfg.Review.SkipGenerated = true
+	diffs := []git.Diff{
+		{FilePath: "main.go"},
+		{FilePath: "main_test.go"},
+		{FilePath: "tmp/server.log"},
+		{FilePath: "go.sum"},
+	}
+
+	kept, err := reviewableDiffs(cfg, diffs)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []git.Diff{{FilePath: "main.go"}}, kept)
+}
+
+func TestReviewableDiffsKeepsTrackedIgnoredFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NoError(t, exec.Command("git", "init", "-q").Run())
+	assert.NoError(t, os.WriteFile(".gitignore", []byte("build/\n"), 0644))
+	assert.NoError(t, os.MkdirAll("build", 0755))
+	assert.NoError(t, os.WriteFile("build/config.go", []byte("package build\n"), 0644))
+	assert.NoError(t, exec.Command("git", "add", "-f", "build/config.go").Run())
+	diffs, err := git.GetStagedDiff()
+	assert.NoError(t, err)
+
+	kept, err := reviewableDiffs(&config.Config{}, diffs)
+
+	assert.NoError(t, err)
+	assert.Len(t, kept, 1, ".gitignore does not apply to tracked files")
+	assert.Equal(t, "build/config.go", kept[0].FilePath)
+}
+
+func TestOutputWritesReportFile(t *testing.T) {
+	out, err := parseOutput("json", filepath.Join(t.TempDir(), "glimpse.json"))
+	assert.NoError(t, err)
+	assert.True(t, out.terminal(), "the styled report still goes to the terminal")
+	assert.True(t, out.writesReport())
+
+	cfg := &config.Config{LLM: config.LLMConfig{Provider: "openai", Model: "gpt-4o"}}
+	result := &review.Result{Findings: []review.Finding{
+		{File: "a.go", StartLine: 1, Severity: review.SeverityHigh, Category: "bug", Message: "kept"},
+		{File: "a.go", StartLine: 2, Severity: review.SeverityLow, Category: "style", Message: "filtered"},
+	}}
+	findings, ok := out.emit(cfg, review.Request{Title: "T"}, result, review.Filter{MinSeverity: review.SeverityMedium})
+	assert.True(t, ok)
+	assert.Len(t, findings, 1)
+
+	data, err := os.ReadFile(out.path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"message": "kept"`)
+	assert.NotContains(t, string(data), "filtered")
+}
+
+func TestOutputFormats(t *testing.T) {
+	out, err := parseOutput("text", "")
+	assert.NoError(t, err)
+	assert.True(t, out.terminal())
+	assert.False(t, out.writesReport())
+
+	out, err = parseOutput("sarif", "")
+	assert.NoError(t, err)
+	assert.False(t, out.terminal(), "stdout is reserved for the report")
+	assert.Equal(t, os.Stderr, out.status())
+
+	_, err = parseOutput("html", "")
+	assert.Error(t, err)
+}
+
+func TestFailOnSeverity(t *testing.T) {
+	cfg := &config.Config{Hook: config.HookConfig{FailOn: "high"}}
+
+	gate, err := failOnSeverity(cfg, "", false)
+	assert.NoError(t, err)
+	assert.Empty(t, gate, "findings never fail a plain headless run")
+
+	gate, err = failOnSeverity(cfg, "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, review.SeverityHigh, gate, "CI mode defaults to hook.fail_on")
+
+	gate, err = failOnSeverity(cfg, "Medium", true)
+	assert.NoError(t, err)
+	assert.Equal(t, review.SeverityMedium, gate)
+
+	_, err = failOnSeverity(cfg, "urgent", false)
+	assert.Error(t, err)
+}
+
+func TestHeadlessRequestWithBaseOnDetachedHead(t *testing.T) {
+	t.Chdir(t.TempDir())
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t", "-c", "commit.gpgsign=false"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	run("init", "-q", "-b", "main")
+	assert.NoError(t, os.WriteFile("a.go", []byte("package a\n"), 0644))
+	run("add", ".")
+	run("commit", "-qm", "base")
+	run("checkout", "-q", "--detach")
+	assert.NoError(t, os.WriteFile("b.go", []byte("package b\n"), 0644))
+	run("add", ".")
+	run("commit", "-qm", "add b")
+
+	req, err := headlessRequest(&config.Config{}, "main")
+
+	assert.NoError(t, err)
+	assert.Len(t, req.Diffs, 1)
+	assert.Equal(t, "b.go", req.Diffs[0].FilePath)
+	assert.Len(t, req.Commits, 1)
+
+	_, err = headlessRequest(&config.Config{}, "origin/main")
+	assert.Error(t, err)
+}
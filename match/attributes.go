@@ -0,0 +1,75 @@
+package match
+
+import (
+	"strings"
+)
+
+// attrRule is one line of a .gitattributes file, reduced to the attributes
+// that decide whether a file is reviewed. nil means the line leaves it unchanged.
+type attrRule struct {
+	pattern   string
+	generated *bool // linguist-generated
+	noDiff    *bool // -diff, or the binary macro
+}
+
+// Attributes holds the .gitattributes rules collected from a working tree
+type Attributes struct {
+	rules []attrRule
+}
+
+// Add appends the rules of a .gitattributes located in dir (slash-separated,
+// relative to the root; "" for the root itself)
+func (a *Attributes) Add(dir string, lines []string) {
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "!") {
+			continue // Comments, bare patterns and (invalid) negated patterns
+		}
+
+		r := attrRule{pattern: anchor(dir, strings.TrimSuffix(fields[0], "/"))}
+		for _, attr := range fields[1:] {
+			switch attr {
+			case "linguist-generated", "linguist-generated=true":
+				r.generated = boolPtr(true)
+			case "-linguist-generated", "!linguist-generated", "linguist-generated=false":
+				r.generated = boolPtr(false)
+			case "-diff", "binary":
+				r.noDiff = boolPtr(true)
+			case "!diff":
+				r.noDiff = boolPtr(false)
+			default:
+				if attr == "diff" || strings.HasPrefix(attr, "diff=") {
+					r.noDiff = boolPtr(false)
+				}
+			}
+		}
+		if r.generated != nil || r.noDiff != nil {
+			a.rules = append(a.rules, r)
+		}
+	}
+}
+
+// Skipped reports whether a root-relative file is marked linguist-generated
+// or -diff. Later rules override earlier ones, as in git.
+func (a *Attributes) Skipped(rel string) bool {
+	if a == nil {
+		return false
+	}
+	generated, noDiff := false, false
+	for _, r := range a.rules {
+		if !Glob(r.pattern, rel) {
+			continue
+		}
+		if r.generated != nil {
+			generated = *r.generated
+		}
+		if r.noDiff != nil {
+			noDiff = *r.noDiff
+		}
+	}
+	return generated || noDiff
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,31 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributes(t *testing.T) {
+	var a Attributes
+	a.Add("", []string{
+		"# generated code",
+		"*.gen.go linguist-generated",
+		"api/*.json linguist-generated=true",
+		"api/keep.json -linguist-generated",
+		"*.svg -diff",
+		"*.png binary",
+		"*.go text eol=lf",
+	})
+	a.Add("web", []string{"dist/** linguist-generated"})
+
+	assert.True(t, a.Skipped("models.gen.go"))
+	assert.True(t, a.Skipped("pkg/models.gen.go"))
+	assert.True(t, a.Skipped("api/spec.json"))
+	assert.False(t, a.Skipped("api/keep.json"), "later rules override")
+	assert.True(t, a.Skipped("img/logo.svg"))
+	assert.True(t, a.Skipped("logo.png"))
+	assert.False(t, a.Skipped("main.go"))
+	assert.True(t, a.Skipped("web/dist/app.js"))
+	assert.False(t, a.Skipped("dist/app.js"))
+}
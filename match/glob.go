@@ -0,0 +1,90 @@
+// Package match decides which files are watched and reviewed, using
+// doublestar globs, .gitignore, .glimpseignore and .gitattributes.
+package match
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Glob reports whether name matches a doublestar pattern. Patterns and names
+// are slash-separated; "*", "?" and "[...]" match within one path segment and
+// "**" matches zero or more whole segments. "./" prefixes are ignored.
+func Glob(pattern, name string) bool {
+	return matchSegments(split(pattern), split(name))
+}
+
+// Base returns the longest leading directory of pattern without glob
+// characters, which is the directory a watcher has to start from
+func Base(pattern string) string {
+	var base []string
+	for _, segment := range split(pattern) {
+		if hasMeta(segment) {
+			break
+		}
+		base = append(base, segment)
+	}
+	if len(base) == len(split(pattern)) && len(base) > 0 {
+		base = base[:len(base)-1] // A literal pattern names a file; watch its directory
+	}
+
+	dir := strings.Join(base, "/")
+	if strings.HasPrefix(filepath.ToSlash(pattern), "/") {
+		dir = "/" + dir
+	}
+	if dir == "" {
+		return "."
+	}
+	return filepath.FromSlash(dir)
+}
+
+// Recursive reports whether pattern can match below the directory after Base
+func Recursive(pattern string) bool {
+	segments := split(pattern)
+	for i, segment := range segments {
+		if segment == "**" || (hasMeta(segment) && i < len(segments)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// split cleans a path or pattern into its segments
+func split(p string) []string {
+	p = path.Clean(filepath.ToSlash(p))
+	if p == "." || p == "/" {
+		return nil
+	}
+	return strings.Split(strings.TrimPrefix(p, "/"), "/")
+}
+
+// hasMeta reports whether a segment contains glob characters
+func hasMeta(segment string) bool {
+	return strings.ContainsAny(segment, "*?[\\")
+}
+
+// matchSegments matches pattern segments against name segments, expanding "**"
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for len(pattern) > 1 && pattern[1] == "**" {
+				pattern = pattern[1:]
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
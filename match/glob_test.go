@@ -0,0 +1,42 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "pkg/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "pkg/a/main.go", true},
+		{"./internal/**/*.go", "internal/x/y/z.go", true},
+		{"./internal/**/*.go", "internal/z.go", true},
+		{"./internal/**/*.go", "pkg/z.go", false},
+		{"vendor/**", "vendor/a/b.go", true},
+		{"src/*_test.go", "src/a_test.go", true},
+		{"src/*_test.go", "src/a/b_test.go", false},
+		{"**/testdata/**", "a/testdata/x/y.json", true},
+		{"/tmp/w/**", "/tmp/w/debug.log", true},
+		{"[ab].go", "c.go", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, Glob(test.pattern, test.name), "%s ~ %s", test.pattern, test.name)
+	}
+}
+
+func TestBaseAndRecursive(t *testing.T) {
+	assert.Equal(t, "internal", Base("./internal/**/*.go"))
+	assert.True(t, Recursive("./internal/**/*.go"))
+	assert.Equal(t, ".", Base("*.go"))
+	assert.False(t, Recursive("*.go"))
+	assert.Equal(t, "cmd", Base("cmd/main.go"))
+	assert.True(t, Recursive("cmd/*/main.go"))
+	assert.Equal(t, "/tmp/w", Base("/tmp/w/**"))
+}
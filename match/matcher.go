@@ -0,0 +1,263 @@
+package match
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultSkips are files that are rarely worth reviewing: lockfiles, vendored
+// dependencies and generated protobuf code
+var DefaultSkips = []string{
+	"**/go.sum",
+	"**/package-lock.json",
+	"**/yarn.lock",
+	"**/pnpm-lock.yaml",
+	"**/bun.lockb",
+	"**/Cargo.lock",
+	"**/Gemfile.lock",
+	"**/poetry.lock",
+	"**/Pipfile.lock",
+	"**/composer.lock",
+	"**/vendor/**",
+	"**/node_modules/**",
+	"**/third_party/**",
+	"**/*.pb.go",
+	"**/*.pb.gw.go",
+	"**/*_pb2.py",
+	"**/*_pb2_grpc.py",
+	"**/*.pb.cc",
+	"**/*.pb.h",
+	"**/*_pb.js",
+	"**/*_pb.d.ts",
+}
+
+// Options configures a Matcher
+type Options struct {
+	Root     string   // Working tree root; ignore and attribute files are read below it
+	Ignore   []string // Extra globs, matched against the path and its file name
+	Defaults bool     // Also skip DefaultSkips
+	// Exclude and Attributes are the repository's info/exclude and
+	// info/attributes, by default those in Root/.git
+	Exclude    string
+	Attributes string
+}
+
+// Matcher decides which files are watched and reviewed. It combines the
+// configured ignore globs, .glimpseignore, the linguist-generated and -diff
+// attributes of .gitattributes, and optionally DefaultSkips. As in git,
+// .gitignore and info/exclude only apply to untracked files, so they are only
+// used to decide what is watched.
+type Matcher struct {
+	root          string
+	ignore        []string
+	gitIgnore     Rules
+	glimpseIgnore Rules
+	attributes    Attributes
+	sources       []source        // Every ignore and attribute file looked for
+	dirs          map[string]bool // Directories walked, relative to the root
+}
+
+// source is an ignore or attribute file as it was when the matcher was built
+type source struct {
+	path    string
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+// New reads the ignore and attribute files below opts.Root
+func New(opts Options) (*Matcher, error) {
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Matcher{root: root, ignore: opts.Ignore, dirs: make(map[string]bool)}
+	if opts.Defaults {
+		m.ignore = append(append([]string{}, opts.Ignore...), DefaultSkips...)
+	}
+	exclude, attributes := opts.Exclude, opts.Attributes
+	if exclude == "" {
+		exclude = filepath.Join(root, ".git", "info", "exclude")
+	}
+	if attributes == "" {
+		attributes = filepath.Join(root, ".git", "info", "attributes")
+	}
+
+	// As in git, .gitignore files take precedence over info/exclude
+	lines, err := m.read(exclude)
+	if err != nil {
+		return nil, err
+	}
+	m.gitIgnore.Add("", lines)
+
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			rel = ""
+		} else if m.skipDir(rel) {
+			return filepath.SkipDir
+		}
+		m.dirs[rel] = true
+
+		for _, file := range []struct {
+			name string
+			add  func(string, []string)
+		}{
+			{".gitignore", m.gitIgnore.Add},
+			{".glimpseignore", m.glimpseIgnore.Add},
+			{".gitattributes", m.attributes.Add},
+		} {
+			lines, err := m.read(filepath.Join(p, file.name))
+			if err != nil {
+				return err
+			}
+			file.add(rel, lines)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Unlike info/exclude, info/attributes takes precedence over the working tree files
+	if lines, err = m.read(attributes); err != nil {
+		return nil, err
+	}
+	m.attributes.Add("", lines)
+	return m, nil
+}
+
+// Stale reports whether the matcher no longer reflects the working tree: an
+// ignore or attribute file changed, appeared or disappeared, or one of paths
+// lies in a directory created since, which may hold new ones
+func (m *Matcher) Stale(paths ...string) bool {
+	for _, s := range m.sources {
+		info, err := os.Stat(s.path)
+		if (err == nil) != s.exists || err == nil && (!info.ModTime().Equal(s.modTime) || info.Size() != s.size) {
+			return true
+		}
+	}
+
+	for _, path := range paths {
+		rel, ok := m.rel(path)
+		if !ok {
+			continue
+		}
+		segments := strings.Split(rel, "/")
+		for i := 1; i < len(segments); i++ {
+			dir := strings.Join(segments[:i], "/")
+			if m.dirs[dir] {
+				continue
+			}
+			if m.skipDir(dir) {
+				break // Never walked
+			}
+			if info, err := os.Stat(filepath.Join(m.root, dir)); err == nil && info.IsDir() {
+				return true
+			}
+			break // Deleted since
+		}
+	}
+	return false
+}
+
+// read returns the lines of an ignore or attribute file, recording it as a source
+func (m *Matcher) read(file string) ([]string, error) {
+	s := source{path: file}
+	if info, err := os.Stat(file); err == nil {
+		s.exists, s.modTime, s.size = true, info.ModTime(), info.Size()
+	}
+	m.sources = append(m.sources, s)
+	return readLines(file)
+}
+
+// Skip reports whether the changes to a file should not be reviewed.
+// .gitignore is not consulted: a tracked file is reviewed even below an
+// ignored directory. Relative paths are relative to the root, as git prints
+// them; paths outside the root are never skipped.
+func (m *Matcher) Skip(path string) bool {
+	rel, ok := m.rel(path)
+	return ok && (m.skipPath(rel, false) || m.attributes.Skipped(rel))
+}
+
+// SkipWatch reports whether saving a file should not trigger a review: Skip,
+// or it is ignored by .gitignore or info/exclude
+func (m *Matcher) SkipWatch(path string) bool {
+	rel, ok := m.rel(path)
+	return ok && (m.gitIgnore.Ignored(rel, false) || m.skipPath(rel, false) || m.attributes.Skipped(rel))
+}
+
+// SkipDir reports whether a directory is not watched, including those
+// ignored by .gitignore or info/exclude
+func (m *Matcher) SkipDir(path string) bool {
+	rel, ok := m.rel(path)
+	return ok && m.skipDir(rel)
+}
+
+// skipDir is SkipDir for a root-relative path
+func (m *Matcher) skipDir(rel string) bool {
+	return m.gitIgnore.Ignored(rel, true) || m.skipPath(rel, true)
+}
+
+// skipPath applies the ignore globs and .glimpseignore to a root-relative path
+func (m *Matcher) skipPath(rel string, isDir bool) bool {
+	if rel == ".git" || strings.HasPrefix(rel, ".git/") {
+		return true
+	}
+	for _, pattern := range m.ignore {
+		if Glob(pattern, rel) || Glob(pattern, filepath.Base(rel)) {
+			return true
+		}
+		// Directory globs such as "vendor/**" also cover the directory itself
+		if isDir && strings.HasSuffix(pattern, "/**") && Glob(strings.TrimSuffix(pattern, "/**"), rel) {
+			return true
+		}
+	}
+	return m.glimpseIgnore.Ignored(rel, isDir)
+}
+
+// rel converts a path to a slash-separated path relative to the root
+func (m *Matcher) rel(path string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.root, path)
+	}
+	rel, err := filepath.Rel(m.root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// readLines returns the lines of a file, or nothing if it does not exist
+func readLines(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
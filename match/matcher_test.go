@@ -0,0 +1,106 @@
+package match
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFile creates a file below root, including its directories
+func writeFile(t *testing.T, root, name, content string) {
+	path := filepath.Join(root, name)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestMatcher(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, ".gitignore", "*.tmp\n")
+	writeFile(t, root, "pkg/.gitignore", "gen/\n")
+	writeFile(t, root, ".glimpseignore", "migrations/**\n")
+	writeFile(t, root, ".gitattributes", "*.snap linguist-generated\n")
+	writeFile(t, root, ".git/info/exclude", "secret.txt\n")
+
+	m, err := New(Options{Root: root, Ignore: []string{"*_test.go"}, Defaults: true})
+	assert.NoError(t, err)
+
+	skipped := []string{
+		"main_test.go",
+		"pkg/a_test.go",
+		"migrations/001.sql",
+		"ui/__snapshots__/app.snap",
+		"go.sum",
+		"web/package-lock.json",
+		"vendor/github.com/x/y.go",
+		"api/v1/service.pb.go",
+		".git/config",
+	}
+	for _, path := range skipped {
+		assert.True(t, m.Skip(path), path)
+		assert.True(t, m.SkipWatch(path), path)
+	}
+
+	// .gitignore only applies to untracked files, i.e. to watching
+	ignored := []string{"a.tmp", "pkg/gen/x.go", "secret.txt", filepath.Join(root, "a.tmp")}
+	for _, path := range ignored {
+		assert.False(t, m.Skip(path), path)
+		assert.True(t, m.SkipWatch(path), path)
+	}
+
+	reviewed := []string{"main.go", "pkg/a.go", "api/v1/service.go", filepath.Join(root, "main.go")}
+	for _, path := range reviewed {
+		assert.False(t, m.Skip(path), path)
+	}
+
+	assert.True(t, m.SkipDir(filepath.Join(root, "vendor")))
+	assert.True(t, m.SkipDir("pkg/gen"))
+	assert.False(t, m.SkipDir("pkg"))
+}
+
+func TestMatcherWithoutDefaults(t *testing.T) {
+	m, err := New(Options{Root: t.TempDir()})
+	assert.NoError(t, err)
+
+	assert.False(t, m.Skip("go.sum"))
+	assert.False(t, m.Skip("vendor/x.go"))
+}
+
+func TestMatcherGitignoreOverridesInfoExclude(t *testing.T) {
+	root := t.TempDir()
+	info := t.TempDir() // e.g. the common dir of a linked worktree
+	writeFile(t, info, "exclude", "*.log\n")
+	writeFile(t, info, "attributes", "*.txt -diff\n")
+	writeFile(t, root, ".gitignore", "!keep.log\n")
+	writeFile(t, root, ".gitattributes", "*.txt diff\n")
+
+	m, err := New(Options{Root: root, Exclude: filepath.Join(info, "exclude"), Attributes: filepath.Join(info, "attributes")})
+	assert.NoError(t, err)
+
+	assert.True(t, m.SkipWatch("debug.log"))
+	assert.False(t, m.SkipWatch("keep.log"), ".gitignore takes precedence over info/exclude")
+	assert.True(t, m.Skip("notes.txt"), "info/attributes takes precedence over .gitattributes")
+}
+
+func TestMatcherStale(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, ".gitignore", "*.tmp\n")
+	writeFile(t, root, "pkg/a.go", "package pkg\n")
+
+	m, err := New(Options{Root: root, Defaults: true})
+	assert.NoError(t, err)
+	assert.False(t, m.Stale("main.go", "pkg/a.go", "gone/a.go", "vendor/x/y.go"))
+
+	writeFile(t, root, "cmd/main.go", "package main\n")
+	assert.True(t, m.Stale("cmd/main.go"), "new directories may hold ignore files")
+	assert.False(t, m.Stale("main.go"))
+
+	writeFile(t, root, "pkg/.gitattributes", "*.gen.go linguist-generated\n")
+	assert.True(t, m.Stale(), "an ignore or attribute file appeared")
+
+	m, err = New(Options{Root: root, Defaults: true})
+	assert.NoError(t, err)
+	writeFile(t, root, ".gitignore", "*.tmp\n*.bak\n")
+	assert.True(t, m.Stale(), "an ignore file changed")
+}
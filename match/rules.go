@@ -0,0 +1,89 @@
+package match
+
+import (
+	"path"
+	"strings"
+)
+
+// rule is one line of a .gitignore-style file
+type rule struct {
+	pattern string // Doublestar pattern relative to the repository root
+	negate  bool
+	dirOnly bool
+}
+
+// Rules holds .gitignore-style rules collected from a working tree
+type Rules struct {
+	rules []rule
+}
+
+// Add appends the rules of an ignore file located in dir (slash-separated,
+// relative to the root; "" for the root itself)
+func (r *Rules) Add(dir string, lines []string) {
+	for _, line := range lines {
+		if parsed, ok := parseRule(dir, line); ok {
+			r.rules = append(r.rules, parsed)
+		}
+	}
+}
+
+// Ignored reports whether a slash-separated path relative to the root is
+// ignored. As in git, nothing inside an ignored directory can be re-included.
+func (r *Rules) Ignored(rel string, isDir bool) bool {
+	if r == nil {
+		return false
+	}
+	segments := split(rel)
+	for i := 1; i < len(segments); i++ {
+		if r.match(strings.Join(segments[:i], "/"), true) {
+			return true
+		}
+	}
+	return r.match(strings.Join(segments, "/"), isDir)
+}
+
+// match applies the rules to one path; the last matching rule wins
+func (r *Rules) match(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range r.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if Glob(rule.pattern, rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// parseRule converts a .gitignore line into a rule anchored at dir
+func parseRule(dir, line string) (rule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	var r rule
+	if strings.HasPrefix(line, "!") {
+		r.negate, line = true, line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:] // Escaped leading "#" or "!"
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly, line = true, strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+	r.pattern = anchor(dir, line)
+	return r, true
+}
+
+// anchor turns a pattern from a file in dir into a root-relative pattern.
+// Without a slash the pattern matches at any depth below dir.
+func anchor(dir, pattern string) string {
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return path.Join(dir, strings.TrimPrefix(pattern, "/"))
+}
@@ -0,0 +1,34 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRules(t *testing.T) {
+	var r Rules
+	r.Add("", []string{
+		"# comment",
+		"*.log",
+		"!keep.log",
+		"build/",
+		"/root-only.txt",
+		"docs/*.html",
+	})
+	r.Add("web", []string{"node_modules"})
+
+	assert.True(t, r.Ignored("server.log", false))
+	assert.True(t, r.Ignored("a/b/server.log", false))
+	assert.False(t, r.Ignored("keep.log", false))
+	assert.True(t, r.Ignored("build", true))
+	assert.False(t, r.Ignored("build", false), "directory-only rule")
+	assert.True(t, r.Ignored("build/out/main.go", false), "inside an ignored directory")
+	assert.True(t, r.Ignored("root-only.txt", false))
+	assert.False(t, r.Ignored("sub/root-only.txt", false))
+	assert.True(t, r.Ignored("docs/index.html", false))
+	assert.False(t, r.Ignored("docs/api/index.html", false))
+	assert.True(t, r.Ignored("web/node_modules/react/index.js", false))
+	assert.False(t, r.Ignored("node_modules/x.js", false), "nested rules apply below their directory")
+	assert.False(t, r.Ignored("main.go", false))
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/revrost/glimpse/config"
+	"github.com/revrost/glimpse/report"
+	"github.com/revrost/glimpse/review"
+	"github.com/revrost/glimpse/styles"
+)
+
+// output selects how one-shot reviews (headless, review and hooks) report findings
+type output struct {
+	format report.Format
+	path   string // Report file; empty writes the report to stdout
+}
+
+// addOutputFlags registers --format and --output
+func addOutputFlags(flags *flag.FlagSet) (format *string, path *string) {
+	format = flags.String("format", string(report.FormatText), "Report format: text, json, sarif or markdown")
+	path = flags.String("output", "", "Write the report to this file instead of stdout")
+	return format, path
+}
+
+// parseOutput validates the --format and --output flags
+func parseOutput(format string, path string) (output, error) {
+	f, err := report.ParseFormat(format)
+	if err != nil {
+		return output{}, err
+	}
+	return output{format: f, path: path}, nil
+}
+
+// terminal reports whether stdout shows the styled report. Otherwise stdout
+// carries the machine-readable report and everything else goes to stderr.
+func (o output) terminal() bool {
+	return o.format == report.FormatText || o.path != ""
+}
+
+// writesReport reports whether a report is written besides the terminal output
+func (o output) writesReport() bool {
+	return o.format != report.FormatText || o.path != ""
+}
+
+// status is where progress and notes are printed
+func (o output) status() io.Writer {
+	if o.terminal() {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// emit prints the findings of a review and writes the report. It returns the
+// filtered findings, and false when the review produced no usable findings.
+func (o output) emit(cfg *config.Config, req review.Request, result *review.Result, filter review.Filter) ([]review.Finding, bool) {
+	var findings []review.Finding
+	if o.terminal() {
+		var ok bool
+		if findings, ok = reportFindings(result, filter, ""); !ok {
+			return nil, false
+		}
+	} else {
+		if !printReviewNotes(os.Stderr, result) {
+			return nil, false
+		}
+		findings = filter.Apply(result.Findings)
+	}
+
+	if err := o.write(cfg, req, findings); err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return nil, false
+	}
+	return findings, true
+}
+
+// write writes the report when one was requested
+func (o output) write(cfg *config.Config, req review.Request, findings []review.Finding) error {
+	if !o.writesReport() {
+		return nil
+	}
+
+	r := report.Report{
+		Title:       req.Title,
+		ToolVersion: version,
+		Provider:    cfg.LLM.Provider,
+		Model:       cfg.LLM.Model,
+		Findings:    findings,
+	}
+	for _, d := range req.Diffs {
+		r.Files = append(r.Files, d.FilePath)
+	}
+	if o.path == "" {
+		return report.Write(os.Stdout, o.format, r)
+	}
+
+	f, err := os.Create(o.path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	err = report.Write(f, o.format, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrSecretDetected is returned when a secret is found and blocking is enabled
+var ErrSecretDetected = errors.New("secret detected in staged changes")
+
+// Kind tells credentials apart from personal data
+type Kind string
+
+// Kinds of sensitive data
+const (
+	KindSecret Kind = "secret"
+	KindPII    Kind = "pii"
+)
+
+// Detector finds one type of sensitive data
+type Detector struct {
+	Name    string
+	Kind    Kind
+	Pattern *regexp.Regexp // When the pattern has a group, only the first group is masked
+}
+
+// builtinDetectors are always enabled
+var builtinDetectors = []Detector{
+	{
+		// Without an END marker, e.g. when a hunk ends inside the key, the
+		// rest of the hunk or input is masked
+		Name:    "private-key",
+		Kind:    KindSecret,
+		Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?:[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----|[^\n]*(?:\n+[^@\n][^\n]*)*)`),
+	},
+	{
+		Name:    "aws-access-key",
+		Kind:    KindSecret,
+		Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}\b`),
+	},
+	{
+		Name:    "aws-secret-key",
+		Kind:    KindSecret,
+		Pattern: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`),
+	},
+	{
+		Name:    "jwt",
+		Kind:    KindSecret,
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`),
+	},
+	{
+		Name:    "email",
+		Kind:    KindPII,
+		Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+	},
+}
+
+// Entropy detection settings
+const (
+	entropyName      = "high-entropy"
+	entropyMinLength = 32
+	entropyThreshold = 4.2 // Bits per character; random base64 scores ~4.7, identifiers well below
+)
+
+// entropyCandidate matches base64/url-safe tokens long enough to be keys
+var entropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/_-]{32,}={0,2}`)
+
+// Pattern is a user-defined detector from the config file
+type Pattern struct {
+	Name  string
+	Regex string
+	Kind  Kind // Defaults to KindSecret
+}
+
+// Config configures a Redactor
+type Config struct {
+	Patterns      []Pattern
+	Entropy       bool // Mask high-entropy strings
+	BlockOnSecret bool // Refuse to review changes containing secrets
+}
+
+// Redactor masks sensitive data before it is sent to an LLM
+type Redactor struct {
+	detectors     []Detector
+	entropy       bool
+	blockOnSecret bool
+}
+
+// Match records one masked value
+type Match struct {
+	Detector string
+	Kind     Kind
+	Source   string // File path or "logs"
+}
+
+// New creates a Redactor with the built-in detectors plus the configured patterns
+func New(config Config) (*Redactor, error) {
+	detectors := append([]Detector(nil), builtinDetectors...)
+	for _, pattern := range config.Patterns {
+		if pattern.Name == "" {
+			return nil, fmt.Errorf("redact pattern %q needs a name", pattern.Regex)
+		}
+		re, err := regexp.Compile(pattern.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %s: %w", pattern.Name, err)
+		}
+		kind := pattern.Kind
+		switch kind {
+		case "":
+			kind = KindSecret
+		case KindSecret, KindPII:
+		default:
+			return nil, fmt.Errorf("invalid kind %q for redact pattern %s (expected secret or pii)", kind, pattern.Name)
+		}
+		detectors = append(detectors, Detector{Name: pattern.Name, Kind: kind, Pattern: re})
+	}
+
+	return &Redactor{
+		detectors:     detectors,
+		entropy:       config.Entropy,
+		blockOnSecret: config.BlockOnSecret,
+	}, nil
+}
+
+// Redact masks every detected value in text, attributing matches to source
+func (r *Redactor) Redact(source string, text string) (string, []Match) {
+	var matches []Match
+	for _, detector := range r.detectors {
+		var n int
+		text, n = mask(text, detector)
+		for i := 0; i < n; i++ {
+			matches = append(matches, Match{Detector: detector.Name, Kind: detector.Kind, Source: source})
+		}
+	}
+
+	if r.entropy {
+		placeholder := placeholder(entropyName)
+		text = entropyCandidate.ReplaceAllStringFunc(text, func(token string) string {
+			if !isHighEntropy(token) {
+				return token
+			}
+			matches = append(matches, Match{Detector: entropyName, Kind: KindSecret, Source: source})
+			return placeholder
+		})
+	}
+	return text, matches
+}
+
+// Blocks reports whether the matches should stop the review
+func (r *Redactor) Blocks(matches []Match) bool {
+	if !r.blockOnSecret {
+		return false
+	}
+	for _, match := range matches {
+		if match.Kind == KindSecret {
+			return true
+		}
+	}
+	return false
+}
+
+// mask replaces the detector's matches and returns how many were replaced
+func mask(text string, detector Detector) (string, int) {
+	locations := detector.Pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(locations) == 0 {
+		return text, 0
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range locations {
+		start, end := loc[0], loc[1]
+		if len(loc) >= 4 && loc[2] >= 0 {
+			start, end = loc[2], loc[3] // Mask only the captured value
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(maskLines(text[start:end], placeholder(detector.Name)))
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String(), len(locations)
+}
+
+// maskLines replaces a possibly multi-line value line by line, so line numbers
+// and diff markers (+, -, space) in the surrounding text stay intact
+func maskLines(value string, placeholder string) string {
+	lines := strings.Split(value, "\n")
+	for i, line := range lines {
+		prefix := ""
+		if i > 0 && line != "" && strings.ContainsRune("+- ", rune(line[0])) {
+			prefix = line[:1]
+		}
+		lines[i] = prefix + placeholder
+	}
+	return strings.Join(lines, "\n")
+}
+
+// placeholder is the text substituted for a masked value
+func placeholder(name string) string {
+	return "[REDACTED:" + name + "]"
+}
+
+// isHighEntropy reports whether token looks like a random key rather than a word or path
+func isHighEntropy(token string) bool {
+	if len(token) < entropyMinLength {
+		return false
+	}
+	var upper, lower, digit bool
+	for _, c := range token {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+	}
+	if !upper || !lower || !digit {
+		return false // Hex digests and plain words lack one of the classes
+	}
+	return shannonEntropy(token) >= entropyThreshold
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, c := range s {
+		counts[c]++
+	}
+	var entropy float64
+	length := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Summary describes what was masked, e.g. "2 aws-access-key in config.go, 1 email in logs"
+func Summary(matches []Match) string {
+	type key struct{ detector, source string }
+	counts := make(map[key]int)
+	var keys []key
+	for _, match := range matches {
+		k := key{match.Detector, match.Source}
+		if counts[k] == 0 {
+			keys = append(keys, k)
+		}
+		counts[k]++
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].source < keys[j].source })
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%d %s in %s", counts[k], k.detector, k.source))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,135 @@
+// Package report writes review findings as text, markdown, JSON or SARIF for
+// CI systems, code scanning and editors.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/revrost/glimpse/review"
+)
+
+// Format is an output format of a report
+type Format string
+
+// Supported formats
+const (
+	FormatText     Format = "text"
+	FormatJSON     Format = "json"
+	FormatSARIF    Format = "sarif"
+	FormatMarkdown Format = "markdown"
+)
+
+// SchemaVersion is the version of the JSON report. It only changes when a
+// field is removed or changes meaning; new fields may be added at any time.
+const SchemaVersion = 1
+
+// ParseFormat parses a format name (case-insensitive)
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(s))); format {
+	case FormatText, FormatJSON, FormatSARIF, FormatMarkdown:
+		return format, nil
+	case "md":
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("invalid format %q (expected text, json, sarif or markdown)", s)
+	}
+}
+
+// Report is the outcome of one review
+type Report struct {
+	Title       string // e.g. "PRE-COMMIT REVIEW"
+	ToolVersion string
+	Provider    string
+	Model       string
+	Findings    []review.Finding
+	Files       []string // Files reviewed, in order (optional)
+}
+
+// Write writes the report in the given format. Text is the markdown report
+// without terminal styling.
+func Write(w io.Writer, format Format, r Report) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, r)
+	case FormatSARIF:
+		return writeSARIF(w, r)
+	case FormatText, FormatMarkdown:
+		return writeMarkdown(w, r)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// jsonReport is the versioned JSON schema. It is decoupled from
+// review.Finding so that internal changes don't break consumers.
+type jsonReport struct {
+	Version  int           `json:"version"`
+	Tool     jsonTool      `json:"tool"`
+	Title    string        `json:"title,omitempty"`
+	Provider string        `json:"provider,omitempty"`
+	Model    string        `json:"model,omitempty"`
+	Summary  jsonSummary   `json:"summary"`
+	Findings []jsonFinding `json:"findings"`
+}
+
+type jsonTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type jsonSummary struct {
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"by_severity"`
+}
+
+type jsonFinding struct {
+	File         string `json:"file,omitempty"`
+	StartLine    int    `json:"start_line,omitempty"`
+	EndLine      int    `json:"end_line,omitempty"`
+	Severity     string `json:"severity"`
+	Category     string `json:"category"`
+	Message      string `json:"message"`
+	SuggestedFix string `json:"suggested_fix,omitempty"`
+}
+
+// writeJSON writes the report as indented JSON
+func writeJSON(w io.Writer, r Report) error {
+	out := jsonReport{
+		Version:  SchemaVersion,
+		Tool:     jsonTool{Name: "glimpse", Version: r.ToolVersion},
+		Title:    r.Title,
+		Provider: r.Provider,
+		Model:    r.Model,
+		Summary:  jsonSummary{Total: len(r.Findings), BySeverity: make(map[string]int)},
+		Findings: make([]jsonFinding, 0, len(r.Findings)),
+	}
+	for _, f := range r.Findings {
+		out.Summary.BySeverity[string(f.Severity)]++
+		out.Findings = append(out.Findings, jsonFinding{
+			File:         f.File,
+			StartLine:    f.StartLine,
+			EndLine:      f.EndLine,
+			Severity:     string(f.Severity),
+			Category:     f.Category,
+			Message:      f.Message,
+			SuggestedFix: f.SuggestedFix,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// writeMarkdown writes a markdown document with a heading, the summary and the findings
+func writeMarkdown(w io.Writer, r Report) error {
+	title := r.Title
+	if title == "" {
+		title = "Glimpse review"
+	}
+	_, err := fmt.Fprintf(w, "# %s\n\n%s\n\n%s\n", title, review.Summary(r.Findings), strings.TrimRight(review.FormatMarkdown(r.Findings), "\n"))
+	return err
+}
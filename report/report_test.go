@@ -0,0 +1,104 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/revrost/glimpse/review"
+	"github.com/stretchr/testify/assert"
+)
+
+var sample = Report{
+	Title:       "PRE-COMMIT REVIEW",
+	ToolVersion: "1.2.3",
+	Provider:    "openai",
+	Model:       "gpt-4o",
+	Findings: []review.Finding{
+		{File: "main.go", StartLine: 12, EndLine: 14, Severity: review.SeverityHigh, Category: "error-handling", Message: "Error ignored", SuggestedFix: "Return it"},
+		{Severity: review.SeverityLow, Category: "style", Message: "General note"},
+	},
+	Files: []string{"main.go", "util.go"},
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]Format{"text": FormatText, "JSON": FormatJSON, "sarif": FormatSARIF, "md": FormatMarkdown} {
+		got, err := ParseFormat(input)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, Write(&b, FormatJSON, sample))
+
+	var got map[string]any
+	assert.NoError(t, json.Unmarshal(b.Bytes(), &got))
+	assert.Equal(t, float64(SchemaVersion), got["version"])
+	assert.Equal(t, map[string]any{"name": "glimpse", "version": "1.2.3"}, got["tool"])
+	assert.Equal(t, map[string]any{"total": float64(2), "by_severity": map[string]any{"high": float64(1), "low": float64(1)}}, got["summary"])
+
+	findings := got["findings"].([]any)
+	assert.Equal(t, map[string]any{
+		"file": "main.go", "start_line": float64(12), "end_line": float64(14),
+		"severity": "high", "category": "error-handling", "message": "Error ignored", "suggested_fix": "Return it",
+	}, findings[0])
+	assert.NotContains(t, findings[1], "file")
+
+	// No findings is an empty list, not null
+	b.Reset()
+	assert.NoError(t, Write(&b, FormatJSON, Report{}))
+	assert.Contains(t, b.String(), `"findings": []`)
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, Write(&b, FormatSARIF, sample))
+
+	var log sarifLog
+	assert.NoError(t, json.Unmarshal(b.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	run := log.Runs[0]
+	assert.Equal(t, "glimpse", run.Tool.Driver.Name)
+	assert.Equal(t, "glimpse/error-handling", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "ErrorHandling", run.Tool.Driver.Rules[0].Name)
+
+	first := run.Results[0]
+	assert.Equal(t, "glimpse/error-handling", first.RuleID)
+	assert.Equal(t, "error", first.Level)
+	assert.Equal(t, "Error ignored\n\nSuggested fix: Return it", first.Message.Text)
+	location := first.Locations[0].PhysicalLocation
+	assert.Equal(t, "main.go", location.ArtifactLocation.URI)
+	assert.Equal(t, &sarifRegion{StartLine: 12, EndLine: 14}, location.Region)
+	assert.NotEmpty(t, first.PartialFingerprints["glimpse/v1"])
+
+	second := run.Results[1]
+	assert.Equal(t, 1, second.RuleIndex)
+	assert.Equal(t, "note", second.Level)
+	location = second.Locations[0].PhysicalLocation
+	assert.Equal(t, "main.go", location.ArtifactLocation.URI, "general findings go to the first reviewed file")
+	assert.Equal(t, &sarifRegion{StartLine: 1}, location.Region)
+
+	// Code scanning rejects results without a location
+	for _, r := range []Report{sample, {Findings: sample.Findings}} {
+		b.Reset()
+		assert.NoError(t, Write(&b, FormatSARIF, r))
+		log = sarifLog{}
+		assert.NoError(t, json.Unmarshal(b.Bytes(), &log))
+		for _, result := range log.Runs[0].Results {
+			assert.NotEmpty(t, result.Locations, result.Message.Text)
+		}
+	}
+	assert.Len(t, log.Runs[0].Results, 1, "without reviewed files a general finding has nowhere to go")
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, Write(&b, FormatMarkdown, sample))
+
+	assert.Contains(t, b.String(), "# PRE-COMMIT REVIEW\n\n2 findings (1 high, 1 low)\n\n")
+	assert.Contains(t, b.String(), "- **HIGH** `main.go:12-14` [error-handling] Error ignored\n  - Fix: Return it\n")
+}
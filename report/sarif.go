@@ -0,0 +1,203 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/revrost/glimpse/review"
+)
+
+// SARIF 2.1.0, the subset GitHub code scanning and editors read
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolURI      = "https://github.com/revrost/glimpse"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	Name                 string       `json:"name"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration sarifConfig  `json:"defaultConfiguration"`
+}
+
+type sarifConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]string `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// writeSARIF writes the report as a SARIF log with one rule per category.
+// Code scanning requires a location for every result, so general findings are
+// placed at the start of the first reviewed file, or left out without one.
+func writeSARIF(w io.Writer, r Report) error {
+	driver := sarifDriver{Name: "glimpse", Version: r.ToolVersion, InformationURI: toolURI, Rules: []sarifRule{}}
+	rules := make(map[string]int)
+	results := make([]sarifResult, 0, len(r.Findings))
+
+	for _, f := range r.Findings {
+		location, ok := sarifLocationOf(f, r.Files)
+		if !ok {
+			continue
+		}
+
+		id := ruleID(f.Category)
+		index, ok := rules[id]
+		if !ok {
+			index = len(driver.Rules)
+			rules[id] = index
+			driver.Rules = append(driver.Rules, sarifRule{
+				ID:                   id,
+				Name:                 ruleName(f.Category),
+				ShortDescription:     sarifMessage{Text: "Glimpse " + categoryName(f.Category) + " finding"},
+				DefaultConfiguration: sarifConfig{Level: "warning"},
+			})
+		}
+
+		message := f.Message
+		if f.SuggestedFix != "" {
+			message += "\n\nSuggested fix: " + f.SuggestedFix
+		}
+		result := sarifResult{
+			RuleID:              id,
+			RuleIndex:           index,
+			Level:               sarifLevel(f.Severity),
+			Message:             sarifMessage{Text: message},
+			Locations:           []sarifLocation{{PhysicalLocation: location}},
+			PartialFingerprints: map[string]string{"glimpse/v1": fingerprint(f)},
+			Properties:          map[string]string{"severity": string(f.Severity)},
+		}
+		results = append(results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
+
+// sarifLocationOf returns where a finding is reported: its own file and lines,
+// or line 1 of the first reviewed file for a general finding
+func sarifLocationOf(f review.Finding, files []string) (sarifPhysicalLocation, bool) {
+	file := f.File
+	region := &sarifRegion{StartLine: f.StartLine, EndLine: max(f.EndLine, f.StartLine)}
+	if file == "" {
+		if len(files) == 0 {
+			return sarifPhysicalLocation{}, false
+		}
+		file, region = files[0], &sarifRegion{StartLine: 1}
+	} else if f.StartLine <= 0 {
+		region = nil // The whole file
+	}
+	return sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifact{URI: filepath.ToSlash(file), URIBaseID: "%SRCROOT%"},
+		Region:           region,
+	}, true
+}
+
+// ruleID names the rule of a category, e.g. "glimpse/error-handling"
+func ruleID(category string) string {
+	if category == "" {
+		category = review.CategoryOther
+	}
+	return "glimpse/" + category
+}
+
+// ruleName is the PascalCase rule name SARIF viewers show, e.g. "ErrorHandling"
+func ruleName(category string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(categoryName(category), " ") {
+		if word != "" {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// categoryName turns a category into words, e.g. "error handling"
+func categoryName(category string) string {
+	if category == "" {
+		category = review.CategoryOther
+	}
+	return strings.ReplaceAll(category, "-", " ")
+}
+
+// sarifLevel maps a severity onto the SARIF levels error, warning and note
+func sarifLevel(severity review.Severity) string {
+	switch severity {
+	case review.SeverityCritical, review.SeverityHigh:
+		return "error"
+	case review.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// fingerprint identifies a finding across runs independently of its lines,
+// so code scanning can track it as code moves
+func fingerprint(f review.Finding) string {
+	message := strings.Join(strings.Fields(strings.ToLower(f.Message)), " ")
+	h := sha256.Sum256([]byte(f.File + "\x00" + f.Category + "\x00" + message))
+	return hex.EncodeToString(h[:16])
+}
@@ -0,0 +1,92 @@
+package review
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/revrost/glimpse/git"
+)
+
+// Cache stores the findings of a reviewed file under a key from CacheKey.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) ([]Finding, bool)
+	Put(key string, findings []Finding) error
+}
+
+// cacheVersion changes whenever cached findings would no longer be valid,
+// e.g. after a change to the findings format
+const cacheVersion = "1"
+
+// CacheKey identifies the review of one file diff by a model with a prompt and task
+func CacheKey(model, systemPrompt, task string, d git.Diff) string {
+	h := sha256.New()
+	for _, part := range []string{cacheVersion, model, systemPrompt, task, d.FilePath, normalizeDiff(d.Content)} {
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeDiff drops what changes without the diff changing: blob IDs on the
+// index line and line ending style
+func normalizeDiff(content string) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	kept := lines[:0]
+	inHeader := true
+	for _, line := range lines {
+		if strings.HasPrefix(line, "@@ ") {
+			inHeader = false
+		}
+		if inHeader && strings.HasPrefix(line, "index ") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// store caches the findings of a successfully reviewed chunk per file.
+// Findings that belong to no file of the chunk are stored with every file,
+// and files split across chunks are not cached since no chunk saw all of them.
+func (r *Reviewer) store(diffs []git.Diff, keys []string, chunks []Chunk, chunk Chunk, findings []Finding) {
+	if r.Cache == nil {
+		return
+	}
+
+	parts := make(map[string]int)
+	for _, c := range chunks {
+		for _, d := range c.Files {
+			parts[d.FilePath]++
+		}
+	}
+	inChunk := make(map[string]bool)
+	for _, d := range chunk.Files {
+		inChunk[d.FilePath] = true
+	}
+
+	byFile := make(map[string][]Finding)
+	var general []Finding
+	for _, finding := range findings {
+		if inChunk[finding.File] {
+			byFile[finding.File] = append(byFile[finding.File], finding)
+		} else {
+			general = append(general, finding)
+		}
+	}
+
+	for _, d := range chunk.Files {
+		if parts[d.FilePath] > 1 {
+			continue
+		}
+		for i, original := range diffs {
+			if original.FilePath != d.FilePath {
+				continue
+			}
+			// Always store a non-nil list so "no findings" is cached too
+			cached := append(append(make([]Finding, 0), byFile[d.FilePath]...), general...)
+			r.Cache.Put(keys[i], cached) // A failed write only costs a later cache miss
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryCache is an in-memory Cache
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string][]Finding
+}
+
+func (c *memoryCache) Get(key string) ([]Finding, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	findings, ok := c.entries[key]
+	return findings, ok
+}
+
+func (c *memoryCache) Put(key string, findings []Finding) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string][]Finding)
+	}
+	c.entries[key] = findings
+	return nil
+}
+
+func TestCacheKeyNormalizesDiff(t *testing.T) {
+	d := git.Diff{FilePath: "a.go", Content: "diff --git a/a.go b/a.go\nindex 1111111..2222222 100644\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-a\n+b\n"}
+	key := CacheKey("openai:gpt", "prompt", "task", d)
+
+	moved := d
+	moved.Content = strings.ReplaceAll(strings.Replace(d.Content, "1111111..2222222", "3333333..4444444", 1), "\n", "\r\n")
+	assert.Equal(t, key, CacheKey("openai:gpt", "prompt", "task", moved), "blob IDs and line endings do not matter")
+
+	changed := d
+	changed.Content = strings.Replace(d.Content, "+b", "+c", 1)
+	assert.NotEqual(t, key, CacheKey("openai:gpt", "prompt", "task", changed))
+	assert.NotEqual(t, key, CacheKey("openai:other", "prompt", "task", d))
+	assert.NotEqual(t, key, CacheKey("openai:gpt", "prompt", "other task", d))
+}
+
+func TestReviewerReusesCachedFiles(t *testing.T) {
+	fake := &llm.Fake{Respond: func(req llm.GenerateRequest) (string, error) {
+		var findings []string
+		for _, line := range strings.Split(req.Context, "\n") {
+			if path, ok := strings.CutPrefix(line, "File: "); ok {
+				findings = append(findings, fmt.Sprintf(`{"file":%q,"start_line":1,"severity":"high","category":"bug","message":"bug"}`, path))
+			}
+		}
+		return `{"findings":[` + strings.Join(findings, ",") + `]}`, nil
+	}}
+	reviewer := &Reviewer{Client: llm.NewWithProvider(llm.Config{Provider: "openai"}, fake), Cache: &memoryCache{}}
+	diffs := []git.Diff{
+		{FilePath: "a.go", Content: fileDiff("a.go", "@@ -1 +1 @@\n+a\n")},
+		{FilePath: "b.go", Content: fileDiff("b.go", "@@ -1 +1 @@\n+b\n")},
+	}
+
+	first, err := reviewer.Review(context.Background(), Request{Diffs: diffs, Task: "t"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, first.Cached)
+	assert.Len(t, first.Findings, 2)
+
+	second, err := reviewer.Review(context.Background(), Request{Diffs: diffs, Task: "t"})
+	assert.NoError(t, err)
+	assert.Len(t, fake.Requests(), 1, "an unchanged review makes no request")
+	assert.Equal(t, 0, second.Chunks)
+	assert.Equal(t, 2, second.Cached)
+	assert.Equal(t, first.Findings, second.Findings)
+
+	diffs[1].Content = fileDiff("b.go", "@@ -1 +1 @@\n+changed\n")
+	third, err := reviewer.Review(context.Background(), Request{Diffs: diffs, Task: "t"})
+	assert.NoError(t, err)
+	assert.Len(t, fake.Requests(), 2)
+	assert.NotContains(t, fake.Requests()[1].Context, "File: a.go", "only the changed file is sent")
+	assert.Equal(t, 1, third.Cached)
+	assert.Len(t, third.Findings, 2)
+}
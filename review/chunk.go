@@ -0,0 +1,131 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/revrost/glimpse/git"
+)
+
+// Chunk is a group of file diffs reviewed in a single LLM request
+type Chunk struct {
+	Files  []git.Diff
+	Tokens int // Estimated tokens of the rendered files
+}
+
+// Render formats the chunk's diffs for the prompt
+func (c Chunk) Render() string {
+	var b strings.Builder
+	for _, d := range c.Files {
+		fmt.Fprintf(&b, "File: %s%s\n%s\n\n", d.FilePath, describe(d), d.Content)
+	}
+	return b.String()
+}
+
+// describe notes what happened to a file when it is more than an edit
+func describe(d git.Diff) string {
+	switch {
+	case d.Status == git.StatusRenamed && d.OldPath != "":
+		return fmt.Sprintf(" (renamed from %s)", d.OldPath)
+	case d.Status == git.StatusCopied && d.OldPath != "":
+		return fmt.Sprintf(" (copied from %s)", d.OldPath)
+	case d.Status == git.StatusAdded:
+		return " (new file)"
+	case d.Status == git.StatusDeleted:
+		return " (deleted)"
+	case d.Binary:
+		return " (binary)"
+	}
+	return ""
+}
+
+// Plan splits diffs into chunks of at most budget estimated tokens.
+// Files are packed in order and a file's hunks stay in the same chunk unless
+// the file alone exceeds the budget, in which case it is split at hunk
+// boundaries with its header repeated in every part. A single hunk larger
+// than the budget gets a chunk of its own. A budget <= 0 disables splitting.
+func Plan(diffs []git.Diff, budget int, estimate func(string) int) []Chunk {
+	var files []git.Diff
+	for _, d := range diffs {
+		if strings.TrimSpace(d.Content) != "" {
+			files = append(files, d)
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	var chunks []Chunk
+	var current Chunk
+	flush := func() {
+		if len(current.Files) > 0 {
+			chunks = append(chunks, current)
+			current = Chunk{}
+		}
+	}
+	add := func(d git.Diff, tokens int) {
+		if budget > 0 && current.Tokens+tokens > budget {
+			flush()
+		}
+		current.Files = append(current.Files, d)
+		current.Tokens += tokens
+	}
+
+	for _, file := range files {
+		tokens := estimate(renderFile(file))
+		if budget <= 0 || tokens <= budget {
+			add(file, tokens)
+			continue
+		}
+
+		// Oversized file: start a fresh chunk and pack its hunks
+		flush()
+		for _, part := range splitHunks(file, budget, estimate) {
+			add(part, estimate(renderFile(part)))
+		}
+		flush()
+	}
+	flush()
+	return chunks
+}
+
+// renderFile formats a single file diff the way Chunk.Render does
+func renderFile(d git.Diff) string {
+	return Chunk{Files: []git.Diff{d}}.Render()
+}
+
+// splitHunks splits an oversized file diff into parts of consecutive hunks that
+// fit the budget. Every part repeats the file header so it can be reviewed alone.
+func splitHunks(d git.Diff, budget int, estimate func(string) int) []git.Diff {
+	if len(d.Hunks) <= 1 {
+		return []git.Diff{d} // A single hunk cannot be split further
+	}
+
+	header := fileHeader(d.Content)
+	part := func(hunks []git.Hunk) git.Diff {
+		p := d
+		p.Content, p.Hunks = joinHunks(header, hunks), hunks
+		return p
+	}
+
+	var parts []git.Diff
+	start := 0
+	for end := start + 1; end < len(d.Hunks); end++ {
+		if estimate(renderFile(part(d.Hunks[start:end+1]))) > budget {
+			parts = append(parts, part(d.Hunks[start:end]))
+			start = end
+		}
+	}
+	return append(parts, part(d.Hunks[start:]))
+}
+
+// fileHeader returns the lines of a file diff before its first hunk
+func fileHeader(content string) string {
+	if strings.HasPrefix(content, "@@ ") {
+		return ""
+	}
+	if i := strings.Index(content, "\n@@ "); i >= 0 {
+		return content[:i+1]
+	}
+	return content
+}
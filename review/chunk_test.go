@@ -0,0 +1,117 @@
+package review
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/revrost/glimpse/git"
+	"github.com/stretchr/testify/assert"
+)
+
+// lines estimates one token per line to keep budgets readable
+func lines(s string) int {
+	return strings.Count(s, "\n")
+}
+
+// parsed builds a git.Diff with hunks from a single-file patch
+func parsed(t *testing.T, content string) git.Diff {
+	t.Helper()
+	diffs, err := git.ParseUnified(content)
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 1)
+	return diffs[0]
+}
+
+func fileDiff(path string, hunks ...string) string {
+	content := "diff --git a/" + path + " b/" + path + "\n--- a/" + path + "\n+++ b/" + path + "\n"
+	for _, hunk := range hunks {
+		content += hunk
+	}
+	return content
+}
+
+func TestPlanPacksFilesInOrder(t *testing.T) {
+	diffs := []git.Diff{
+		{FilePath: "a.go", Content: fileDiff("a.go", "@@ -1 +1 @@\n+a\n")},
+		{FilePath: "b.go", Content: fileDiff("b.go", "@@ -1 +1 @@\n+b\n")},
+		{FilePath: "c.go", Content: fileDiff("c.go", "@@ -1 +1 @@\n+c\n")},
+	}
+
+	chunks := Plan(diffs, 20, lines)
+
+	assert.Len(t, chunks, 2)
+	assert.Equal(t, []string{"a.go", "b.go"}, paths(chunks[0]))
+	assert.Equal(t, []string{"c.go"}, paths(chunks[1]))
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, chunk.Tokens, 20)
+	}
+}
+
+func TestPlanNoBudget(t *testing.T) {
+	diffs := []git.Diff{
+		{FilePath: "a.go", Content: fileDiff("a.go", "@@ -1 +1 @@\n+a\n")},
+		{FilePath: "b.go", Content: fileDiff("b.go", "@@ -1 +1 @@\n+b\n")},
+	}
+
+	chunks := Plan(diffs, 0, lines)
+
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, []string{"a.go", "b.go"}, paths(chunks[0]))
+}
+
+func TestPlanSkipsEmptyDiffs(t *testing.T) {
+	diffs := []git.Diff{
+		{FilePath: "a.go", Content: fileDiff("a.go", "@@ -1 +1 @@\n+a\n")},
+		{FilePath: "empty.go", Content: "\n"},
+	}
+
+	chunks := Plan(diffs, 0, lines)
+
+	assert.Equal(t, []string{"a.go"}, paths(chunks[0]))
+}
+
+func TestPlanSplitsOversizedFileAtHunks(t *testing.T) {
+	hunk := "@@ -1,3 +1,3 @@\n+1\n+2\n+3\n"
+	big := parsed(t, fileDiff("big.go", hunk, hunk, hunk, hunk))
+	small := git.Diff{FilePath: "small.go", Content: fileDiff("small.go", "@@ -1 +1 @@\n+s\n")}
+
+	chunks := Plan([]git.Diff{small, big}, 16, lines)
+
+	// small.go, then big.go in two parts of two hunks each
+	assert.Len(t, chunks, 3)
+	assert.Equal(t, []string{"small.go"}, paths(chunks[0]))
+	for _, chunk := range chunks[1:] {
+		assert.Equal(t, []string{"big.go"}, paths(chunk))
+		assert.True(t, strings.HasPrefix(chunk.Files[0].Content, "diff --git a/big.go b/big.go\n"), "header repeated")
+		assert.Equal(t, 2, strings.Count(chunk.Files[0].Content, "@@ -1,3"))
+		assert.Len(t, chunk.Files[0].Hunks, 2)
+		assert.LessOrEqual(t, chunk.Tokens, 16)
+	}
+}
+
+func TestPlanOversizedHunkGetsOwnChunk(t *testing.T) {
+	huge := parsed(t, fileDiff("huge.go", "@@ -1 +1,9 @@\n"+strings.Repeat("+x\n", 9)))
+
+	chunks := Plan([]git.Diff{huge}, 5, lines)
+
+	assert.Len(t, chunks, 1)
+	assert.Greater(t, chunks[0].Tokens, 5)
+}
+
+func TestRenderDescribesStatus(t *testing.T) {
+	renamed := parsed(t, "diff --git a/old.go b/new.go\nsimilarity index 90%\nrename from old.go\nrename to new.go\n")
+	added := parsed(t, "diff --git a/n.go b/n.go\nnew file mode 100644\n--- /dev/null\n+++ b/n.go\n@@ -0,0 +1 @@\n+n\n")
+
+	out := Chunk{Files: []git.Diff{renamed, added}}.Render()
+
+	assert.Contains(t, out, "File: new.go (renamed from old.go)\n")
+	assert.Contains(t, out, "File: n.go (new file)\n")
+}
+
+func paths(chunk Chunk) []string {
+	var out []string
+	for _, file := range chunk.Files {
+		out = append(out, file.FilePath)
+	}
+	return out
+}
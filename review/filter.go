@@ -0,0 +1,41 @@
+package review
+
+import (
+	"sort"
+	"strings"
+)
+
+// Filter selects which findings are reported
+type Filter struct {
+	MinSeverity Severity // Findings below this severity are dropped (empty keeps all)
+	Categories  []string // Only these categories are kept (empty keeps all)
+}
+
+// Apply returns the findings matching the filter, most severe first
+func (f Filter) Apply(findings []Finding) []Finding {
+	kept := make([]Finding, 0, len(findings))
+	for _, finding := range findings {
+		if f.MinSeverity != "" && !finding.Severity.AtLeast(f.MinSeverity) {
+			continue
+		}
+		if len(f.Categories) > 0 && !containsFold(f.Categories, finding.Category) {
+			continue
+		}
+		kept = append(kept, finding)
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].Severity.Rank() > kept[j].Severity.Rank()
+	})
+	return kept
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterApply(t *testing.T) {
+	findings := []Finding{
+		{Message: "style nit", Severity: SeverityInfo, Category: "style"},
+		{Message: "sql injection", Severity: SeverityCritical, Category: "security"},
+		{Message: "slow loop", Severity: SeverityMedium, Category: "performance"},
+		{Message: "nil deref", Severity: SeverityHigh, Category: "bug"},
+	}
+
+	all := Filter{}.Apply(findings)
+	assert.Equal(t, []string{"sql injection", "nil deref", "slow loop", "style nit"}, messages(all))
+
+	severe := Filter{MinSeverity: SeverityMedium}.Apply(findings)
+	assert.Equal(t, []string{"sql injection", "nil deref", "slow loop"}, messages(severe))
+
+	security := Filter{Categories: []string{"Security", "bug"}}.Apply(findings)
+	assert.Equal(t, []string{"sql injection", "nil deref"}, messages(security))
+}
+
+func TestParseSeverity(t *testing.T) {
+	severity, err := ParseSeverity(" Warning ")
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityMedium, severity)
+
+	_, err = ParseSeverity("urgent")
+	assert.Error(t, err)
+
+	assert.True(t, SeverityHigh.AtLeast(SeverityMedium))
+	assert.False(t, SeverityLow.AtLeast(SeverityMedium))
+}
+
+func messages(findings []Finding) []string {
+	var out []string
+	for _, finding := range findings {
+		out = append(out, finding.Message)
+	}
+	return out
+}
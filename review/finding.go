@@ -0,0 +1,143 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity ranks how urgent a finding is
+type Severity string
+
+// Severities from most to least urgent
+const (
+	SeverityCritical Severity = "critical"
+	SeverityHigh     Severity = "high"
+	SeverityMedium   Severity = "medium"
+	SeverityLow      Severity = "low"
+	SeverityInfo     Severity = "info"
+)
+
+// severityOrder lists severities from most to least urgent
+var severityOrder = []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo}
+
+// severityRanks orders severities, higher is more urgent
+var severityRanks = map[Severity]int{
+	SeverityCritical: 5,
+	SeverityHigh:     4,
+	SeverityMedium:   3,
+	SeverityLow:      2,
+	SeverityInfo:     1,
+}
+
+// severityAliases maps common model vocabulary onto our severities
+var severityAliases = map[string]Severity{
+	"blocker": SeverityCritical,
+	"major":   SeverityHigh,
+	"error":   SeverityHigh,
+	"warning": SeverityMedium,
+	"minor":   SeverityLow,
+	"nit":     SeverityInfo,
+	"note":    SeverityInfo,
+}
+
+// ParseSeverity parses a severity name (case-insensitive)
+func ParseSeverity(s string) (Severity, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if _, ok := severityRanks[Severity(name)]; ok {
+		return Severity(name), nil
+	}
+	if severity, ok := severityAliases[name]; ok {
+		return severity, nil
+	}
+	return "", fmt.Errorf("invalid severity %q (expected critical, high, medium, low or info)", s)
+}
+
+// Rank returns the numeric urgency of the severity, 0 if unknown
+func (s Severity) Rank() int {
+	return severityRanks[s]
+}
+
+// AtLeast reports whether s is as urgent as min or more
+func (s Severity) AtLeast(min Severity) bool {
+	return s.Rank() >= min.Rank()
+}
+
+// Categories the model is asked to choose from
+var Categories = []string{
+	"bug",
+	"security",
+	"performance",
+	"concurrency",
+	"error-handling",
+	"maintainability",
+	"style",
+}
+
+// CategoryOther is used for categories outside Categories
+const CategoryOther = "other"
+
+// Finding is a single issue raised by a review
+type Finding struct {
+	File         string   `json:"file"`
+	StartLine    int      `json:"start_line"`
+	EndLine      int      `json:"end_line"`
+	Severity     Severity `json:"severity"`
+	Category     string   `json:"category"`
+	Message      string   `json:"message"`
+	SuggestedFix string   `json:"suggested_fix,omitempty"`
+}
+
+// Location renders the file and line range, e.g. "main.go:12-14"
+func (f Finding) Location() string {
+	switch {
+	case f.File == "":
+		return "general"
+	case f.StartLine == 0:
+		return f.File
+	case f.EndLine <= f.StartLine:
+		return fmt.Sprintf("%s:%d", f.File, f.StartLine)
+	default:
+		return fmt.Sprintf("%s:%d-%d", f.File, f.StartLine, f.EndLine)
+	}
+}
+
+// normalize validates the finding and canonicalises severity, category and lines
+func (f *Finding) normalize() error {
+	f.File = strings.TrimSpace(f.File)
+	f.Message = strings.TrimSpace(f.Message)
+	f.SuggestedFix = strings.TrimSpace(f.SuggestedFix)
+
+	if f.Message == "" {
+		return fmt.Errorf("missing message")
+	}
+
+	severity, err := ParseSeverity(string(f.Severity))
+	if err != nil {
+		return err
+	}
+	f.Severity = severity
+
+	f.Category = normalizeCategory(f.Category)
+
+	if f.StartLine < 0 || f.EndLine < 0 {
+		return fmt.Errorf("negative line number")
+	}
+	if f.EndLine == 0 {
+		f.EndLine = f.StartLine
+	}
+	if f.EndLine < f.StartLine {
+		return fmt.Errorf("end_line %d before start_line %d", f.EndLine, f.StartLine)
+	}
+	return nil
+}
+
+// normalizeCategory lowercases the category and maps unknown values to CategoryOther
+func normalizeCategory(category string) string {
+	category = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(category)), "_", "-")
+	for _, known := range Categories {
+		if category == known {
+			return category
+		}
+	}
+	return CategoryOther
+}
@@ -0,0 +1,60 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Summary returns a one-line count of findings by severity, e.g. "3 findings (1 high, 2 low)"
+func Summary(findings []Finding) string {
+	if len(findings) == 0 {
+		return "No issues found"
+	}
+
+	counts := make(map[Severity]int)
+	for _, finding := range findings {
+		counts[finding.Severity]++
+	}
+
+	var parts []string
+	for _, severity := range severityOrder {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+
+	noun := "findings"
+	if len(findings) == 1 {
+		noun = "finding"
+	}
+	return fmt.Sprintf("%d %s (%s)", len(findings), noun, strings.Join(parts, ", "))
+}
+
+// FormatMarkdown renders findings as a markdown list for the terminal
+func FormatMarkdown(findings []Finding) string {
+	if len(findings) == 0 {
+		return "No issues found."
+	}
+
+	var b strings.Builder
+	for _, finding := range findings {
+		fmt.Fprintf(&b, "- **%s** `%s` [%s] %s\n",
+			strings.ToUpper(string(finding.Severity)), finding.Location(), finding.Category, finding.Message)
+		if finding.SuggestedFix != "" {
+			fmt.Fprintf(&b, "  - Fix: %s\n", finding.SuggestedFix)
+		}
+	}
+	return b.String()
+}
+
+// FormatFixPrompt renders findings as instructions for a fix agent
+func FormatFixPrompt(findings []Finding) string {
+	var b strings.Builder
+	for i, finding := range findings {
+		fmt.Fprintf(&b, "%d. [%s] %s: %s\n", i+1, finding.Severity, finding.Location(), finding.Message)
+		if finding.SuggestedFix != "" {
+			fmt.Fprintf(&b, "   Suggested fix: %s\n", finding.SuggestedFix)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,34 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	assert.Equal(t, "No issues found", Summary(nil))
+	assert.Equal(t, "1 finding (1 low)", Summary([]Finding{{Severity: SeverityLow}}))
+	assert.Equal(t, "3 findings (1 high, 2 info)", Summary([]Finding{
+		{Severity: SeverityInfo},
+		{Severity: SeverityHigh},
+		{Severity: SeverityInfo},
+	}))
+}
+
+func TestFormatFixPrompt(t *testing.T) {
+	prompt := FormatFixPrompt([]Finding{
+		{File: "db.go", StartLine: 4, EndLine: 6, Severity: SeverityCritical, Message: "query is not parameterised", SuggestedFix: "use placeholders"},
+		{Severity: SeverityLow, Message: "missing docs"},
+	})
+
+	assert.Equal(t, "1. [critical] db.go:4-6: query is not parameterised\n"+
+		"   Suggested fix: use placeholders\n"+
+		"2. [low] general: missing docs\n", prompt)
+}
+
+func TestLocation(t *testing.T) {
+	assert.Equal(t, "general", Finding{}.Location())
+	assert.Equal(t, "a.go", Finding{File: "a.go"}.Location())
+	assert.Equal(t, "a.go:3", Finding{File: "a.go", StartLine: 3, EndLine: 3}.Location())
+}
@@ -0,0 +1,226 @@
+package review
+
+import (
+	"strings"
+
+	"github.com/revrost/glimpse/git"
+)
+
+// Baseline is an earlier review of the same kind of changes, e.g. the previous
+// staged review. Its diffs are as sent, i.e. with secrets masked.
+type Baseline struct {
+	Diffs    []git.Diff
+	Findings []Finding
+}
+
+// Changes sorts the findings of a review against its baseline
+type Changes struct {
+	New      []Finding // Not reported by the baseline
+	Open     []Finding // Reported before and still present
+	Resolved []Finding // Reported before, gone now
+}
+
+// Narrow returns the parts of diffs that differ from the baseline: files the
+// baseline did not see and the hunks of other files that it did not see
+// verbatim. Baseline findings that do not overlap a changed hunk are returned
+// as carried, moved to where their lines are now; file-level and general
+// findings are always carried.
+func Narrow(base Baseline, diffs []git.Diff) (changed []git.Diff, carried []Finding) {
+	previous := make(map[string]git.Diff, len(base.Diffs))
+	for _, d := range base.Diffs {
+		previous[d.FilePath] = d
+	}
+
+	for _, d := range diffs {
+		p, ok := previous[d.FilePath]
+		switch {
+		case !ok:
+			changed = append(changed, d)
+		case normalizeDiff(p.Content) == normalizeDiff(d.Content):
+			carried = append(carried, fileFindings(base.Findings, d.FilePath)...)
+		case len(d.Hunks) == 0 || len(p.Hunks) == 0:
+			changed = append(changed, d) // Binary or mode-only change: nothing to compare by hunk
+		default:
+			hunks, kept := interdiff(p, d, base.Findings)
+			carried = append(carried, kept...)
+			if len(hunks) > 0 {
+				n := d
+				n.Content, n.Hunks = joinHunks(fileHeader(d.Content), hunks), hunks
+				changed = append(changed, n)
+			}
+		}
+	}
+	return changed, append(carried, fileFindings(base.Findings, "")...)
+}
+
+// interdiff returns the hunks of d that p does not contain, and the findings
+// of p that do not overlap them: those in the hunks both contain, those
+// outside every hunk and file-level ones
+func interdiff(p, d git.Diff, findings []Finding) ([]git.Hunk, []Finding) {
+	unchanged := make(map[string][]int) // Hunks of p by their lines
+	for i, hunk := range p.Hunks {
+		key := hunkKey(hunk)
+		unchanged[key] = append(unchanged[key], i)
+	}
+
+	var hunks []git.Hunk
+	shifts := make(map[int]int) // Hunks of p that d contains -> lines they moved by
+	for _, hunk := range d.Hunks {
+		key := hunkKey(hunk)
+		if len(unchanged[key]) == 0 {
+			hunks = append(hunks, hunk)
+			continue
+		}
+		i := unchanged[key][0]
+		unchanged[key] = unchanged[key][1:]
+		shifts[i] = hunk.NewStart - p.Hunks[i].NewStart
+	}
+
+	var carried []Finding
+	for _, finding := range fileFindings(findings, d.FilePath) {
+		if finding.StartLine <= 0 {
+			carried = append(carried, finding) // About the whole file
+			continue
+		}
+		shift, ok, inHunk := 0, false, false
+		for i, h := range p.Hunks {
+			if finding.StartLine >= h.NewStart && finding.StartLine < h.NewStart+max(h.NewLines, 1) {
+				inHunk = true
+				shift, ok = shifts[i]
+				break
+			}
+		}
+		if !inHunk {
+			// Outside the hunks: carried unless a changed hunk now covers its lines
+			end := max(finding.EndLine, finding.StartLine)
+			oldStart, oldEnd := toOld(p.Hunks, finding.StartLine), toOld(p.Hunks, end)
+			if overlapsOld(hunks, oldStart, oldEnd) {
+				continue
+			}
+			shift, ok = toNew(d.Hunks, oldStart)-finding.StartLine, true
+		}
+		if !ok {
+			continue // In a hunk that changed, which is reviewed again
+		}
+		finding.StartLine += shift
+		if finding.EndLine > 0 {
+			finding.EndLine += shift
+		}
+		carried = append(carried, finding)
+	}
+	return hunks, carried
+}
+
+// toOld maps a line of the new file that lies outside the hunks to the old file
+func toOld(hunks []git.Hunk, line int) int {
+	shift := 0
+	for _, h := range hunks {
+		if h.NewStart+h.NewLines <= line {
+			shift += h.NewLines - h.OldLines
+		}
+	}
+	return line - shift
+}
+
+// toNew maps a line of the old file that lies outside the hunks to the new file
+func toNew(hunks []git.Hunk, line int) int {
+	shift := 0
+	for _, h := range hunks {
+		if h.OldStart+h.OldLines <= line {
+			shift += h.NewLines - h.OldLines
+		}
+	}
+	return line + shift
+}
+
+// overlapsOld reports whether any hunk changes old lines start to end
+func overlapsOld(hunks []git.Hunk, start, end int) bool {
+	for _, h := range hunks {
+		if start < h.OldStart+max(h.OldLines, 1) && end >= h.OldStart {
+			return true
+		}
+	}
+	return false
+}
+
+// hunkKey identifies a hunk by its lines, independently of where it starts
+func hunkKey(hunk git.Hunk) string {
+	var b strings.Builder
+	for _, line := range hunk.Lines {
+		b.WriteByte(byte(line.Kind))
+		b.WriteString(line.Content)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// joinHunks renders a file diff from its header and some of its hunks
+func joinHunks(header string, hunks []git.Hunk) string {
+	var b strings.Builder
+	b.WriteString(header)
+	for _, hunk := range hunks {
+		b.WriteString(hunk.String())
+	}
+	return b.String()
+}
+
+// fileFindings returns the findings about one file
+func fileFindings(findings []Finding, path string) []Finding {
+	var matched []Finding
+	for _, finding := range findings {
+		if finding.File == path {
+			matched = append(matched, finding)
+		}
+	}
+	return matched
+}
+
+// inScope drops the findings about files that are no longer part of the
+// changes, e.g. since they were committed or unstaged
+func inScope(findings []Finding, diffs []git.Diff) []Finding {
+	paths := make(map[string]bool, len(diffs))
+	for _, d := range diffs {
+		paths[d.FilePath] = true
+	}
+	var kept []Finding
+	for _, finding := range findings {
+		if finding.File == "" || paths[finding.File] {
+			kept = append(kept, finding)
+		}
+	}
+	return kept
+}
+
+// Compare matches the findings of two reviews by file, category and message.
+// Line numbers are ignored since edits elsewhere in a file shift them.
+func Compare(before, after []Finding) Changes {
+	var c Changes
+
+	remaining := make(map[string]int)
+	for _, finding := range before {
+		remaining[findingKey(finding)]++
+	}
+	for _, finding := range after {
+		key := findingKey(finding)
+		if remaining[key] > 0 {
+			remaining[key]--
+			c.Open = append(c.Open, finding)
+		} else {
+			c.New = append(c.New, finding)
+		}
+	}
+	for _, finding := range before {
+		key := findingKey(finding)
+		if remaining[key] > 0 {
+			remaining[key]--
+			c.Resolved = append(c.Resolved, finding)
+		}
+	}
+	return c
+}
+
+// findingKey identifies a finding independently of its position and wording case
+func findingKey(f Finding) string {
+	message := strings.Join(strings.Fields(strings.ToLower(f.Message)), " ")
+	return f.File + "\x00" + f.Category + "\x00" + message
+}
@@ -0,0 +1,109 @@
+package review
+
+import (
+	"context"
+	"testing"
+
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNarrowKeepsChangedHunks(t *testing.T) {
+	first := "@@ -10,2 +10,3 @@ func a() {\n a\n+b\n c\n"
+	second := "@@ -40,2 +41,3 @@ func d() {\n d\n+e\n f\n"
+	base := Baseline{
+		Diffs: []git.Diff{
+			parsed(t, fileDiff("a.go", first)),
+			parsed(t, fileDiff("same.go", first)),
+			parsed(t, fileDiff("gone.go", first)),
+		},
+		Findings: []Finding{
+			{File: "a.go", StartLine: 11, EndLine: 11, Message: "in first hunk"},
+			{File: "a.go", StartLine: 30, EndLine: 31, Message: "outside hunks"},
+			{File: "a.go", Message: "about the file"},
+			{File: "a.go", StartLine: 41, Message: "where a hunk was added"},
+			{File: "same.go", StartLine: 11, Message: "unchanged file"},
+			{Message: "general"},
+		},
+	}
+
+	// A hunk was added above the first one, moving it down by 5 lines
+	added := "@@ -1,2 +1,7 @@\n x\n+1\n+2\n+3\n+4\n+5\n y\n"
+	moved := "@@ -10,2 +15,3 @@ func a() {\n a\n+b\n c\n"
+	diffs := []git.Diff{
+		parsed(t, fileDiff("a.go", added, moved, second)),
+		parsed(t, fileDiff("same.go", first)),
+		parsed(t, fileDiff("new.go", first)),
+	}
+
+	changed, carried := Narrow(base, diffs)
+
+	assert.Len(t, changed, 2)
+	assert.Equal(t, "a.go", changed[0].FilePath)
+	assert.Len(t, changed[0].Hunks, 2, "only the added and the changed hunk are left")
+	assert.Equal(t, 1, changed[0].Hunks[0].NewStart)
+	assert.Equal(t, 41, changed[0].Hunks[1].NewStart)
+	assert.Contains(t, changed[0].Content, "+++ b/a.go\n@@ -1,2 +1,7 @@")
+	assert.NotContains(t, changed[0].Content, "func a()")
+	assert.Equal(t, "new.go", changed[1].FilePath)
+
+	assert.Equal(t, []Finding{
+		{File: "a.go", StartLine: 16, EndLine: 16, Message: "in first hunk"},
+		{File: "a.go", StartLine: 35, EndLine: 36, Message: "outside hunks"},
+		{File: "a.go", Message: "about the file"},
+		{File: "same.go", StartLine: 11, Message: "unchanged file"},
+		{Message: "general"},
+	}, carried)
+}
+
+func TestCompareFindings(t *testing.T) {
+	before := []Finding{
+		{File: "a.go", Category: "bug", Message: "Nil  dereference"},
+		{File: "a.go", Category: "bug", Message: "leak"},
+	}
+	after := []Finding{
+		{File: "a.go", StartLine: 9, Category: "bug", Message: "nil dereference"},
+		{File: "b.go", Category: "style", Message: "naming"},
+	}
+
+	c := Compare(before, after)
+
+	assert.Equal(t, "naming", c.New[0].Message)
+	assert.Equal(t, 9, c.Open[0].StartLine, "still open findings are worded as now")
+	assert.Equal(t, "leak", c.Resolved[0].Message)
+}
+
+func TestReviewerSendsOnlyChangesSinceBaseline(t *testing.T) {
+	fake := &llm.Fake{Content: `{"findings":[{"file":"b.go","start_line":1,"severity":"high","category":"bug","message":"new bug"}]}`}
+	reviewer := &Reviewer{Client: llm.NewWithProvider(llm.Config{}, fake)}
+	a := parsed(t, fileDiff("a.go", "@@ -1 +1 @@\n-a\n+A\n"))
+	base := Baseline{
+		Diffs: []git.Diff{a, parsed(t, fileDiff("b.go", "@@ -1 +1 @@\n-b\n+B\n"))},
+		Findings: []Finding{
+			{File: "a.go", StartLine: 1, Severity: SeverityHigh, Category: "bug", Message: "open bug"},
+			{File: "b.go", StartLine: 1, Severity: SeverityHigh, Category: "bug", Message: "fixed bug"},
+		},
+	}
+	req := Request{Diffs: []git.Diff{a, parsed(t, fileDiff("b.go", "@@ -1 +1 @@\n-b\n+C\n"))}, Baseline: &base}
+
+	result, err := reviewer.Review(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Len(t, fake.Requests(), 1)
+	assert.NotContains(t, fake.Requests()[0].Context, "a.go", "unchanged files are not sent")
+	assert.Equal(t, 1, result.Files)
+	assert.Len(t, result.Findings, 2)
+	assert.Equal(t, "new bug", result.Changes.New[0].Message)
+	assert.Equal(t, "open bug", result.Changes.Open[0].Message)
+	assert.Equal(t, "fixed bug", result.Changes.Resolved[0].Message)
+
+	// Nothing changed since: no request at all
+	base.Diffs, base.Findings = req.Diffs, result.Findings
+	result, err = reviewer.Review(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, fake.Requests(), 1)
+	assert.Equal(t, 0, result.Files)
+	assert.Empty(t, result.Changes.New)
+	assert.Len(t, result.Changes.Open, 2)
+}
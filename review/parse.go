@@ -0,0 +1,82 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// response is the JSON envelope the model is asked to return
+type response struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Parse decodes and validates the findings in a model response.
+// It tolerates markdown code fences and prose around the JSON document.
+// Invalid findings are dropped and reported in the error alongside the valid ones.
+func Parse(content string) ([]Finding, error) {
+	document := ExtractJSON(content)
+	if document == "" {
+		return nil, fmt.Errorf("no JSON object found in response")
+	}
+
+	var findings []Finding
+	if strings.HasPrefix(document, "[") {
+		// Some models drop the envelope and return the array directly
+		if err := json.Unmarshal([]byte(document), &findings); err != nil {
+			return nil, fmt.Errorf("invalid findings JSON: %w", err)
+		}
+	} else {
+		var resp response
+		if err := json.Unmarshal([]byte(document), &resp); err != nil {
+			return nil, fmt.Errorf("invalid findings JSON: %w", err)
+		}
+		findings = resp.Findings
+	}
+
+	var errs []error
+	valid := make([]Finding, 0, len(findings))
+	for i := range findings {
+		if err := findings[i].normalize(); err != nil {
+			errs = append(errs, fmt.Errorf("finding %d: %w", i+1, err))
+			continue
+		}
+		valid = append(valid, findings[i])
+	}
+
+	if len(errs) > 0 {
+		return valid, errors.Join(errs...)
+	}
+	return valid, nil
+}
+
+// ExtractJSON returns the outermost JSON object or array in content, without
+// markdown fences or surrounding prose
+func ExtractJSON(content string) string {
+	content = strings.TrimSpace(content)
+
+	// Strip ```json ... ``` fences
+	if strings.HasPrefix(content, "```") {
+		content = strings.TrimPrefix(content, "```")
+		if newline := strings.Index(content, "\n"); newline >= 0 {
+			content = content[newline+1:]
+		}
+		content = strings.TrimSuffix(strings.TrimSpace(content), "```")
+		content = strings.TrimSpace(content)
+	}
+
+	start := strings.IndexAny(content, "{[")
+	if start < 0 {
+		return ""
+	}
+	closing := "}"
+	if content[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(content, closing)
+	if end < start {
+		return ""
+	}
+	return content[start : end+1]
+}
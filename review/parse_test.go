@@ -0,0 +1,69 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnvelope(t *testing.T) {
+	content := `{"findings":[{"file":"main.go","start_line":12,"end_line":14,"severity":"High","category":"Bug","message":"nil map write","suggested_fix":"initialise the map"}]}`
+
+	findings, err := Parse(content)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Finding{{
+		File:         "main.go",
+		StartLine:    12,
+		EndLine:      14,
+		Severity:     SeverityHigh,
+		Category:     "bug",
+		Message:      "nil map write",
+		SuggestedFix: "initialise the map",
+	}}, findings)
+}
+
+func TestParseFencedBareArray(t *testing.T) {
+	content := "Here is my review:\n```json\n[{\"file\":\"a.go\",\"start_line\":3,\"severity\":\"nit\",\"category\":\"naming\",\"message\":\"rename x\"}]\n```"
+
+	findings, err := Parse(content)
+
+	assert.NoError(t, err)
+	assert.Len(t, findings, 1)
+	assert.Equal(t, SeverityInfo, findings[0].Severity)
+	assert.Equal(t, CategoryOther, findings[0].Category)
+	assert.Equal(t, 3, findings[0].EndLine)
+}
+
+func TestParseNoFindings(t *testing.T) {
+	findings, err := Parse(`{"findings":[]}`)
+
+	assert.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestParseDropsInvalidFindings(t *testing.T) {
+	content := `{"findings":[
+		{"file":"a.go","severity":"urgent","message":"bad severity"},
+		{"file":"a.go","severity":"low","message":""},
+		{"file":"a.go","start_line":9,"end_line":2,"severity":"low","message":"backwards"},
+		{"file":"a.go","severity":"medium","message":"kept"}
+	]}`
+
+	findings, err := Parse(content)
+
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "finding 1: invalid severity")
+	assert.ErrorContains(t, err, "finding 2: missing message")
+	assert.ErrorContains(t, err, "finding 3: end_line 2 before start_line 9")
+	assert.Len(t, findings, 1)
+	assert.Equal(t, "kept", findings[0].Message)
+}
+
+func TestParseNotJSON(t *testing.T) {
+	_, err := Parse("Looks good to me!")
+	assert.ErrorContains(t, err, "no JSON object")
+
+	_, err = Parse(`{"findings": [`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package review
+
+import "strings"
+
+// instructions tell the model how to shape its answer
+const instructions = `
+
+Respond ONLY with a JSON object, without prose or markdown fences, shaped like:
+{"findings":[{"file":"path/to/file.go","start_line":12,"end_line":14,"severity":"high","category":"bug","message":"What is wrong and why it matters","suggested_fix":"The concrete change to make"}]}
+
+Rules:
+- severity is one of: critical, high, medium, low, info
+- category is one of: ` + "{{categories}}" + `
+- start_line and end_line refer to the new version of the file (use the diff hunk headers); use 0 when a finding is not tied to specific lines
+- Only report real issues in the changed code. Return {"findings":[]} when there is nothing to report.`
+
+// SystemPrompt appends the structured output instructions to a base system prompt
+func SystemPrompt(base string) string {
+	return base + strings.Replace(instructions, "{{categories}}", strings.Join(Categories, ", "), 1)
+}
+
+// Schema returns the JSON schema of the expected response, for providers
+// that support schema-constrained output
+func Schema() map[string]interface{} {
+	severities := make([]string, 0, len(severityOrder))
+	for _, severity := range severityOrder {
+		severities = append(severities, string(severity))
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"findings": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"file":          map[string]interface{}{"type": "string"},
+						"start_line":    map[string]interface{}{"type": "integer"},
+						"end_line":      map[string]interface{}{"type": "integer"},
+						"severity":      map[string]interface{}{"type": "string", "enum": severities},
+						"category":      map[string]interface{}{"type": "string", "enum": append(append([]string{}, Categories...), CategoryOther)},
+						"message":       map[string]interface{}{"type": "string"},
+						"suggested_fix": map[string]interface{}{"type": "string"},
+					},
+					"required":             []string{"file", "start_line", "end_line", "severity", "category", "message", "suggested_fix"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"findings"},
+		"additionalProperties": false,
+	}
+}
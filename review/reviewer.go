@@ -0,0 +1,323 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/llm"
+	"github.com/revrost/glimpse/redact"
+)
+
+// Request describes a set of changes to review
+type Request struct {
+	Title   string // Prompt heading, e.g. "STAGED CHANGE REVIEW"
+	Diffs   []git.Diff
+	Commits []git.Commit // Messages of the commits under review (optional)
+	Base    string       // Commit the diffs are taken against, when it isn't HEAD or the index (optional)
+	Logs    string       // Runtime logs sent with every chunk (optional)
+	Task    string
+	Stream  bool // Print findings as they arrive; only honoured when the changes fit in a single chunk
+
+	StagedHash string    // Index hash of a staged review, passed through to OnComplete (optional)
+	Baseline   *Baseline // Earlier review: only hunks it did not see are sent, its other findings carried over (optional)
+}
+
+// Result is the merged outcome of reviewing every chunk
+type Result struct {
+	Findings []Finding
+	Chunks   int            // Requests sent to the LLM
+	Files    int            // Files left to review after narrowing to the baseline
+	Cached   int            // Files whose findings came from the cache
+	Changes  *Changes       // Findings sorted against the baseline, if the request had one
+	Invalid  error          // Findings dropped while parsing, if any
+	Unparsed []string       // Raw responses that held no findings JSON
+	Redacted []redact.Match // Values masked before sending
+	ID       string         // Set by OnComplete when it saves the review, e.g. to the history
+}
+
+// Reviewer splits changes into token-budgeted chunks and reviews them in parallel
+type Reviewer struct {
+	Client         *llm.Client
+	SystemPrompt   string           // Base system prompt; the findings instructions are appended
+	MaxChunkTokens int              // Token budget of a single request, prompt included (0 disables chunking)
+	Concurrency    int              // Maximum requests in flight (at least 1)
+	Redactor       *redact.Redactor // Masks secrets and PII before sending (nil disables)
+	Cache          Cache            // Reuses the findings of files reviewed before (nil disables)
+	// Progress is called when a review is split into several chunks: once with
+	// done=0 before starting and after every completed chunk
+	Progress func(done, total int)
+	// OnComplete is called after every successful review with the request as
+	// sent, i.e. with secrets masked
+	OnComplete func(req Request, result *Result)
+}
+
+// chunkResult holds the outcome of one chunk
+type chunkResult struct {
+	findings []Finding
+	invalid  error
+	raw      string
+}
+
+// Review reviews the changes and merges the findings of every chunk.
+// The first failing chunk cancels the others and its error is returned.
+func (r *Reviewer) Review(ctx context.Context, req Request) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	redacted, err := r.redact(&req)
+	if err != nil {
+		return nil, err
+	}
+	systemPrompt := SystemPrompt(r.SystemPrompt)
+
+	// The prompt around the diff is repeated in every chunk
+	budget := r.MaxChunkTokens
+	if budget > 0 {
+		overhead := r.Client.EstimateTokens(systemPrompt + req.Task + buildContext(req, "", 0, 0))
+		budget = max(budget-overhead, budget/4) // Very long logs: keep room for the diff anyway
+	}
+
+	// Hunks the baseline saw verbatim keep their findings
+	diffs := req.Diffs
+	var carried []Finding
+	if req.Baseline != nil {
+		diffs, carried = Narrow(*req.Baseline, req.Diffs)
+	}
+
+	// Files reviewed before with the same prompt and model are not sent again
+	keys := make([]string, len(diffs))
+	var pending []git.Diff
+	cached := [][]Finding{carried}
+	for i, d := range diffs {
+		keys[i] = CacheKey(r.Client.ModelID(), systemPrompt, req.Task, d)
+		if r.Cache != nil {
+			if findings, ok := r.Cache.Get(keys[i]); ok {
+				cached = append(cached, findings)
+				continue
+			}
+		}
+		pending = append(pending, d)
+	}
+
+	chunks := Plan(pending, budget, r.Client.EstimateTokens)
+	result := &Result{Chunks: len(chunks), Files: len(diffs), Cached: len(cached) - 1, Redacted: redacted}
+	if len(chunks) == 0 {
+		result.Findings = dedupe(cached)
+		r.finish(req, result)
+		return result, nil
+	}
+
+	multi := len(chunks) > 1
+	results := make([]chunkResult, len(chunks))
+	errs := make([]error, len(chunks))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, max(r.Concurrency, 1))
+
+	var mu sync.Mutex
+	completed := 0
+	if multi && r.Progress != nil {
+		r.Progress(0, len(chunks))
+	}
+
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			generate := llm.GenerateRequest{
+				SystemPrompt: systemPrompt,
+				Context:      buildContext(req, chunk.Render(), i+1, len(chunks)),
+				Task:         req.Task,
+				Stream:       req.Stream && !multi,
+				Quiet:        multi,
+				JSONSchema:   Schema(),
+			}
+			if generate.Stream {
+				// Show findings as they arrive rather than the raw JSON
+				generate.StreamTo = newFindingStream(os.Stdout)
+			}
+			resp := <-r.Client.Generate(ctx, generate)
+			if resp.Error != nil {
+				errs[i] = resp.Error
+				cancel()
+				return
+			}
+
+			findings, err := Parse(resp.Content)
+			results[i] = chunkResult{findings: findings, invalid: err}
+			if err != nil && len(findings) == 0 {
+				results[i].raw = resp.Content
+			}
+			if err == nil {
+				r.store(diffs, keys, chunks, chunk, findings)
+			}
+
+			if multi && r.Progress != nil {
+				mu.Lock()
+				completed++
+				r.Progress(completed, len(chunks))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := firstError(errs); err != nil {
+		return nil, err
+	}
+
+	var invalid []error
+	all := cached
+	for i, res := range results {
+		if res.raw != "" {
+			result.Unparsed = append(result.Unparsed, res.raw)
+		}
+		if res.invalid != nil {
+			if multi {
+				res.invalid = fmt.Errorf("chunk %d: %w", i+1, res.invalid)
+			}
+			invalid = append(invalid, res.invalid)
+		}
+		all = append(all, res.findings)
+	}
+	result.Findings = dedupe(all)
+	result.Invalid = errors.Join(invalid...)
+	r.finish(req, result)
+	return result, nil
+}
+
+// finish sorts the findings against the baseline and reports the review to OnComplete
+func (r *Reviewer) finish(req Request, result *Result) {
+	if req.Baseline != nil {
+		changes := Compare(inScope(req.Baseline.Findings, req.Diffs), result.Findings)
+		result.Changes = &changes
+	}
+	if r.OnComplete != nil {
+		r.OnComplete(req, result)
+	}
+}
+
+// dedupe merges findings lists, dropping repeats of the same issue at the same lines
+func dedupe(lists [][]Finding) []Finding {
+	var merged []Finding
+	seen := make(map[string]bool)
+	for _, findings := range lists {
+		for _, finding := range findings {
+			key := fmt.Sprintf("%s:%d:%d:%s", finding.File, finding.StartLine, finding.EndLine, finding.Message)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, finding)
+		}
+	}
+	return merged
+}
+
+// redact masks the diffs, commit messages and logs of req in place. It fails
+// when a secret is in the changes under review and the redactor is configured
+// to block.
+func (r *Reviewer) redact(req *Request) ([]redact.Match, error) {
+	if r.Redactor == nil {
+		return nil, nil
+	}
+
+	var staged []redact.Match
+	diffs := make([]git.Diff, len(req.Diffs))
+	for i, d := range req.Diffs {
+		content, matches := r.Redactor.Redact(d.FilePath, d.Content)
+		diffs[i] = d
+		if len(matches) > 0 {
+			// Re-parse so no hunk still holds the unmasked lines
+			diffs[i].Content, diffs[i].Hunks = content, nil
+			if masked, err := git.ParseUnified(content); err == nil && len(masked) == 1 {
+				diffs[i].Hunks = masked[0].Hunks
+			}
+		}
+		staged = append(staged, matches...)
+	}
+	commits := make([]git.Commit, len(req.Commits))
+	for i, c := range req.Commits {
+		source := fmt.Sprintf("commit %.12s", c.SHA)
+		var subjectMatches, bodyMatches []redact.Match
+		c.Subject, subjectMatches = r.Redactor.Redact(source, c.Subject)
+		c.Body, bodyMatches = r.Redactor.Redact(source, c.Body)
+		commits[i] = c
+		staged = append(append(staged, subjectMatches...), bodyMatches...)
+	}
+	if r.Redactor.Blocks(staged) {
+		return nil, fmt.Errorf("%w: %s", redact.ErrSecretDetected, redact.Summary(staged))
+	}
+
+	logs, matches := r.Redactor.Redact("logs", req.Logs)
+	req.Diffs, req.Logs = diffs, logs
+	if req.Commits != nil {
+		req.Commits = commits
+	}
+	return append(staged, matches...), nil
+}
+
+// buildContext renders the prompt context for one chunk of the request
+func buildContext(req Request, diff string, part, parts int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== %s ===\n", req.Title)
+	if parts > 1 {
+		fmt.Fprintf(&b, "(Part %d of %d: the remaining changes are reviewed separately)\n", part, parts)
+	}
+	if len(req.Commits) > 0 {
+		b.WriteString("=== COMMITS ===\n")
+		for _, commit := range req.Commits {
+			fmt.Fprintf(&b, "%.12s %s\n", commit.SHA, commit.Subject)
+			if commit.Body != "" {
+				fmt.Fprintf(&b, "%s\n", indent(commit.Body))
+			}
+		}
+		b.WriteString("=== CHANGES ===\n")
+	}
+	b.WriteString(diff)
+	if req.Logs != "" {
+		b.WriteString("=== RUNTIME LOGS ===\n")
+		b.WriteString(req.Logs)
+	}
+	return b.String()
+}
+
+// indent prefixes every line of s with four spaces
+func indent(s string) string {
+	return "    " + strings.ReplaceAll(s, "\n", "\n    ")
+}
+
+// firstError returns the first error that isn't a cancellation caused by a
+// sibling chunk, falling back to the cancellation itself
+func firstError(errs []error) error {
+	var cancelled error
+	for _, err := range errs {
+		switch {
+		case err == nil:
+		case errors.Is(err, context.Canceled):
+			if cancelled == nil {
+				cancelled = err
+			}
+		default:
+			return err
+		}
+	}
+	return cancelled
+}
@@ -0,0 +1,75 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// findingStream receives a streamed JSON response. Instead of echoing the raw
+// JSON it prints each finding on one line as soon as its object is complete;
+// the full report is rendered once the response has been parsed.
+type findingStream struct {
+	w        io.Writer
+	open     []byte // Brackets not yet closed
+	finding  []byte // Object of the finding being received
+	depth    int    // Length of open when the finding started, or 0
+	inString bool
+	escaped  bool
+}
+
+// newFindingStream prints the findings of a streamed response to w
+func newFindingStream(w io.Writer) *findingStream {
+	return &findingStream{w: w}
+}
+
+// Write scans p for the objects in the findings array
+func (s *findingStream) Write(p []byte) (int, error) {
+	for _, c := range p {
+		if s.depth > 0 {
+			s.finding = append(s.finding, c)
+		}
+		if s.inString {
+			switch {
+			case s.escaped:
+				s.escaped = false
+			case c == '\\':
+				s.escaped = true
+			case c == '"':
+				s.inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			s.inString = true
+		case '{', '[':
+			// Findings are the objects of an array, at the top level or in the envelope
+			if c == '{' && s.depth == 0 && len(s.open) > 0 && s.open[len(s.open)-1] == '[' {
+				s.depth = len(s.open) + 1
+				s.finding = append(s.finding[:0], c)
+			}
+			s.open = append(s.open, c)
+		case '}', ']':
+			if len(s.open) == 0 {
+				continue
+			}
+			if len(s.open) == s.depth {
+				s.print()
+				s.depth = 0
+			}
+			s.open = s.open[:len(s.open)-1]
+		}
+	}
+	return len(p), nil
+}
+
+// print prints the finding just received, unless it is invalid
+func (s *findingStream) print() {
+	var f Finding
+	if json.Unmarshal(s.finding, &f) != nil || f.normalize() != nil {
+		return
+	}
+	fmt.Fprintf(s.w, "[%s] %s: %s\n", f.Severity, f.Location(), f.Message)
+}
@@ -0,0 +1,32 @@
+package review
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindingStream(t *testing.T) {
+	response := "```json\n" + `{"findings": [
+  {"file": "main.go", "start_line": 12, "severity": "HIGH", "category": "bug", "message": "Nil map {write}"},
+  {"severity": "low", "category": "style", "message": "Say \"why\""},
+  {"file": "a.go", "severity": "low", "category": "style"}
+]}` + "\n```"
+	var out strings.Builder
+	stream := newFindingStream(&out)
+
+	// Tokens arrive in arbitrary pieces
+	write := func(s string) {
+		for i := 0; i < len(s); i += 7 {
+			stream.Write([]byte(s[i:min(i+7, len(s))]))
+		}
+	}
+	second := strings.Index(response, `{"severity"`)
+	write(response[:second])
+	assert.Equal(t, "[high] main.go:12: Nil map {write}\n", out.String(), "printed once its object is complete")
+	write(response[second:])
+
+	assert.Equal(t, "[high] main.go:12: Nil map {write}\n[low] general: Say \"why\"\n", out.String(), "no raw JSON, invalid findings skipped")
+	assert.NotContains(t, out.String(), "findings")
+}
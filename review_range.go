@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/revrost/glimpse/config"
+	"github.com/revrost/glimpse/git"
+	"github.com/revrost/glimpse/review"
+	"github.com/revrost/glimpse/styles"
+)
+
+// runReviewCommand handles "glimpse review --range|--commit|--last" and returns the exit code
+func runReviewCommand(args []string) int {
+	flags := flag.NewFlagSet("review", flag.ContinueOnError)
+	rangeSpec := flags.String("range", "", "Review a range as git diff does: 'main...HEAD' from the merge base, 'A..B' from A to B")
+	commitRev := flags.String("commit", "", "Review a single commit")
+	last := flags.Int("last", 0, "Review the last N commits on HEAD")
+	var provider string
+	flags.StringVar(&provider, "provider", "", "LLM provider and model in format 'provider:model'")
+	flags.StringVar(&provider, "p", "", "Alias for --provider")
+	baseURL := flags.String("base-url", "", "Override the LLM API base URL")
+	minSeverity := flags.String("min-severity", "", "Only report findings at or above this severity")
+	fixMode := flags.Bool("f", false, "Fix mode: run the fix agent to fix the reported findings")
+	yes := flags.Bool("yes", false, "Apply native fix patches and agent fixes without asking for confirmation")
+	streamMode := flags.Bool("s", false, "Stream mode: show findings in real-time")
+	noCache := flags.Bool("no-cache", false, "Review every file again instead of reusing cached findings")
+	format, outputPath := addOutputFlags(flags)
+	failOn := flags.String("fail-on", "", "Exit 3 when findings reach this severity")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	out, err := parseOutput(*format, *outputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 2
+	}
+
+	revision, err := resolveRevision(*rangeSpec, *commitRev, *last)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 2
+	}
+
+	cfg, err := loadReviewConfig(provider, *baseURL, *minSeverity)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+	if *noCache {
+		cfg.Cache.Enabled = false
+	}
+	gate, err := failOnSeverity(cfg, *failOn, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 2
+	}
+	fixer, err := newFixer(cfg, *fixMode, *yes)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+
+	req, err := revisionRequest(cfg, revision)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+		return 1
+	}
+	if len(req.Diffs) == 0 {
+		fmt.Fprintln(out.status(), styles.CreateInfoStyle("No changes to review"))
+		if err := out.write(cfg, req, nil); err != nil {
+			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(err.Error()))
+			return 1
+		}
+		return 0
+	}
+	req.Stream = *streamMode
+
+	fmt.Fprintln(out.status(), styles.Status.Render(fmt.Sprintf(
+		"Reviewing %d commit(s), %d file(s) since %.12s", len(req.Commits), len(req.Diffs), revision.Base,
+	)))
+	return runReviewOnce(cfg, req, fixer, gate, out)
+}
+
+// resolveRevision turns exactly one of --range, --commit or --last into a revision
+func resolveRevision(rangeSpec string, commitRev string, last int) (git.Revision, error) {
+	set := 0
+	for _, given := range []bool{rangeSpec != "", commitRev != "", last != 0} {
+		if given {
+			set++
+		}
+	}
+	if set != 1 {
+		return git.Revision{}, fmt.Errorf("specify exactly one of --range, --commit or --last")
+	}
+
+	switch {
+	case rangeSpec != "":
+		return git.ResolveRange(rangeSpec)
+	case commitRev != "":
+		return git.ResolveCommit(commitRev)
+	default:
+		return git.ResolveLast(last)
+	}
+}
+
+// revisionRequest collects the diffs and commit messages of a revision
+func revisionRequest(cfg *config.Config, revision git.Revision) (review.Request, error) {
+	diffs, err := revision.Diff()
+	if err != nil {
+		return review.Request{}, err
+	}
+	commits, err := revision.Commits()
+	if err != nil {
+		return review.Request{}, err
+	}
+
+	kept, err := reviewableDiffs(cfg, diffs)
+	if err != nil {
+		return review.Request{}, err
+	}
+
+	return review.Request{
+		Title:   "BRANCH REVIEW",
+		Diffs:   kept,
+		Commits: commits,
+		Base:    revision.Base,
+		Task:    "Review these commits as a whole, as a reviewer would before merging. Use the commit messages to judge intent. Flag bugs, security issues and risks. Be concise.",
+	}, nil
+}
@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// plain disables colors and animations. It is set once at startup.
+var plain bool
+
+// ConfigureOutput switches to plain output when stdout is not a terminal or
+// NO_COLOR is set, e.g. in CI logs and when output is piped
+func ConfigureOutput() {
+	if os.Getenv("NO_COLOR") != "" || !IsTerminal(os.Stdout) {
+		SetPlain()
+	}
+}
+
+// SetPlain disables colors, spinners and styled markdown
+func SetPlain() {
+	plain = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// Plain reports whether output is plain text
+func Plain() bool {
+	return plain
+}
+
+// IsTerminal reports whether f is an interactive terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -18,7 +18,7 @@ func PromptProvider() (string, error) {
 	fmt.Printf("  1) OpenAI (GPT-4o, GPT-3.5-turbo)\n")
 	fmt.Printf("  2) Z.AI (GLM-4.6)\n")
 	fmt.Printf("  3) Claude (Claude-3.5-Sonnet)\n")
-	fmt.Printf("  4) Gemini (Coming Soon)\n")
+	fmt.Printf("  4) Gemini (Gemini-2.0-Flash, Gemini-1.5-Pro)\n")
 	fmt.Println(Separator(60))
 	
 	reader := bufio.NewReader(os.Stdin)
@@ -39,7 +39,7 @@ func PromptProvider() (string, error) {
 	case "3", "claude":
 		return "claude", nil
 	case "4", "gemini":
-		return "", fmt.Errorf("Gemini provider is not yet implemented")
+		return "gemini", nil
 	default:
 		return "", fmt.Errorf("invalid selection: %s", input)
 	}
@@ -133,6 +133,33 @@ func PromptModel(provider string) (string, error) {
 				return input, nil
 			}
 		}
+
+	case "gemini":
+		fmt.Println("Available models:")
+		fmt.Printf("  1) gemini-2.0-flash (recommended)\n")
+		fmt.Printf("  2) gemini-1.5-pro\n")
+		fmt.Printf("  3) gemini-1.5-flash\n")
+		fmt.Println(Separator(60))
+		fmt.Print("Enter model number (1-3) or custom model name: ")
+		
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+		
+		input = strings.TrimSpace(input)
+		switch input {
+		case "1":
+			return "gemini-2.0-flash", nil
+		case "2":
+			return "gemini-1.5-pro", nil
+		case "3":
+			return "gemini-1.5-flash", nil
+		default:
+			if input != "" {
+				return input, nil
+			}
+		}
 	}
 	
 	// Default fallback
@@ -162,6 +189,12 @@ func ShowAPIKeyHelp(provider string) {
 		fmt.Printf("  export ANTHROPIC_API_KEY=\"your-api-key-here\"\n\n")
 		fmt.Printf("Or add it to your shell profile (~/.zshrc, ~/.bashrc, etc.)\n\n")
 		fmt.Printf("Get your API key from: https://console.anthropic.com/\n")
+		
+	case "gemini":
+		fmt.Printf("To use Gemini (Google), you need to set your API key:\n\n")
+		fmt.Printf("  export GEMINI_API_KEY=\"your-api-key-here\"\n\n")
+		fmt.Printf("Or add it to your shell profile (~/.zshrc, ~/.bashrc, etc.)\n\n")
+		fmt.Printf("Get your API key from: https://aistudio.google.com/app/apikey\n")
 	}
 	
 	fmt.Println(Separator(60))
@@ -129,8 +129,12 @@ type MarkdownRenderer struct {
 
 // NewMarkdownRenderer creates a new markdown renderer
 func NewMarkdownRenderer() (*MarkdownRenderer, error) {
+	style := glamour.WithAutoStyle()
+	if plain {
+		style = glamour.WithStandardStyle("notty")
+	}
 	renderer, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
+		style,
 		glamour.WithWordWrap(80),
 	)
 	if err != nil {
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,4 +32,12 @@ func TestSpinner(t *testing.T) {
 	assert.NotEmpty(t, firstTick)
 	assert.NotEmpty(t, secondTick)
 	assert.NotEqual(t, firstTick, secondTick) // Should advance frame
-}
\ No newline at end of file
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	assert.False(t, IsTerminal(f), "files and pipes are not terminals")
+}
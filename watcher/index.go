@@ -0,0 +1,172 @@
+package watcher
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/revrost/glimpse/styles"
+)
+
+// DefaultIndexDebounce collapses the burst of writes of a single git command
+const DefaultIndexDebounce = 200 * time.Millisecond
+
+// IndexConfig lists the git files whose changes may change the staged state
+type IndexConfig struct {
+	Files    []string // Files such as .git/index and .git/HEAD
+	Dirs     []string // Directory trees where any file counts, such as .git/refs
+	Debounce time.Duration
+}
+
+// IndexWatcher signals when the git index, HEAD or refs change.
+// Git replaces these files by renaming lock files over them, so the watcher
+// watches their directories and matches on names.
+type IndexWatcher struct {
+	config  IndexConfig
+	watcher *fsnotify.Watcher
+	files   map[string]bool
+	changes chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewIndexWatcher starts watching the configured files and directories
+func NewIndexWatcher(config IndexConfig) (*IndexWatcher, error) {
+	if config.Debounce <= 0 {
+		config.Debounce = DefaultIndexDebounce
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &IndexWatcher{
+		config:  config,
+		watcher: fsWatcher,
+		files:   make(map[string]bool),
+		changes: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	dirs := make(map[string]bool)
+	for _, file := range config.Files {
+		w.files[filepath.Clean(file)] = true
+		dirs[filepath.Dir(filepath.Clean(file))] = true
+	}
+	for dir := range dirs {
+		if err := fsWatcher.Add(dir); err != nil {
+			fsWatcher.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+	for _, root := range config.Dirs {
+		if err := w.addTree(root); err != nil {
+			fsWatcher.Close()
+			return nil, err
+		}
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// Changes receives once per debounced burst of changes
+func (w *IndexWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops the watcher
+func (w *IndexWatcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	return w.watcher.Close()
+}
+
+// addTree watches root and every directory below it
+func (w *IndexWatcher) addTree(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := w.watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// run debounces relevant events into Changes
+func (w *IndexWatcher) run() {
+	timer := time.NewTimer(w.config.Debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !w.relevant(event) {
+				continue
+			}
+			if event.Has(fsnotify.Create) && w.inTree(event.Name) {
+				// New ref directories, e.g. refs/heads/feature/
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					w.addTree(event.Name)
+				}
+			}
+			timer.Reset(w.config.Debounce)
+
+		case <-timer.C:
+			select {
+			case w.changes <- struct{}{}:
+			default: // A change is already pending
+			}
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Index watcher error: %v", err)))
+
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// relevant reports whether an event touches a watched file or tree.
+// Lock files are skipped: the rename that replaces the real file follows.
+func (w *IndexWatcher) relevant(event fsnotify.Event) bool {
+	if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return false
+	}
+	path := filepath.Clean(event.Name)
+	if strings.HasSuffix(path, ".lock") {
+		return false
+	}
+	return w.files[path] || w.inTree(path)
+}
+
+// inTree reports whether path is inside one of the watched directory trees
+func (w *IndexWatcher) inTree(path string) bool {
+	for _, root := range w.config.Dirs {
+		rel, err := filepath.Rel(filepath.Clean(root), path)
+		if err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
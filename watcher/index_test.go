@@ -0,0 +1,62 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexWatcherDebouncesLockRenames(t *testing.T) {
+	gitDir := t.TempDir()
+	index := filepath.Join(gitDir, "index")
+	refs := filepath.Join(gitDir, "refs")
+	assert.NoError(t, os.WriteFile(index, []byte("v1"), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(refs, "heads"), 0755))
+
+	w, err := NewIndexWatcher(IndexConfig{Files: []string{index}, Dirs: []string{refs}, Debounce: 50 * time.Millisecond})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	// git writes index.lock and renames it over the index, several times per command
+	for i := 0; i < 3; i++ {
+		lock := index + ".lock"
+		assert.NoError(t, os.WriteFile(lock, []byte("v2"), 0644))
+		assert.NoError(t, os.Rename(lock, index))
+	}
+
+	select {
+	case <-w.Changes():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for index change")
+	}
+	select {
+	case <-w.Changes():
+		t.Fatal("Burst of writes should produce a single change")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Unrelated files in the git dir are ignored
+	assert.NoError(t, os.WriteFile(filepath.Join(gitDir, "FETCH_HEAD"), []byte("x"), 0644))
+	select {
+	case <-w.Changes():
+		t.Fatal("Unrelated file triggered a change")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Branch refs count, including ones in new directories
+	assert.NoError(t, os.MkdirAll(filepath.Join(refs, "heads", "feature"), 0755))
+	time.Sleep(150 * time.Millisecond)
+	select {
+	case <-w.Changes(): // The new directory itself may count
+	default:
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(refs, "heads", "feature", "x"), []byte("sha"), 0644))
+	select {
+	case <-w.Changes():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for ref change")
+	}
+}
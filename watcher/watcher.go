@@ -2,20 +2,23 @@ package watcher
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/revrost/glimpse/match"
 	"github.com/revrost/glimpse/styles"
 )
 
 // Config holds the watcher configuration
 type Config struct {
-	Watch    []string
+	Watch    []string // Doublestar globs of files to review
 	Ignore   []string
 	Debounce time.Duration
+	Matcher  *match.Matcher // Optional; skipped paths are neither watched nor reported
 }
 
 // Watcher monitors filesystem changes
@@ -23,6 +26,7 @@ type Watcher struct {
 	config  Config
 	watcher *fsnotify.Watcher
 	events  chan FileEvent
+	roots   []string // Directories watched recursively, including new subdirectories
 }
 
 // FileEvent represents a file change event
@@ -43,57 +47,31 @@ func New(config Config) (*Watcher, error) {
 		events:  make(chan FileEvent, 100),
 	}
 
-	// Add watch patterns - watch directories, not files
-	addedDirs := make(map[string]bool)
-	// fmt.Println(styles.Info.Render(fmt.Sprintf("Processing %d watch patterns", len(config.Watch))))
+	// Watch the static base directory of every pattern; fsnotify is not
+	// recursive, so patterns that reach deeper register the whole tree
+	added := make(map[string]bool)
 	for _, pattern := range config.Watch {
-		// fmt.Println(styles.Muted.Render(fmt.Sprintf("Pattern: %s", pattern)))
-
-		// Check if pattern contains ** (recursive)
-		if strings.Contains(pattern, "**") {
-			// Handle recursive pattern
-			baseDir := strings.Split(pattern, "**")[0]
-			baseDir = strings.TrimSuffix(baseDir, "/")
-			if baseDir == "" {
-				baseDir = "."
+		base := match.Base(pattern)
+		if info, err := os.Stat(base); err != nil || !info.IsDir() {
+			if match.Recursive(pattern) {
+				fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Failed to watch directory %s: not a directory", base)))
 			}
+			continue
+		}
 
-			// fmt.Println(styles.Text.Render(fmt.Sprintf("Adding directory for recursive pattern: %s", baseDir)))
-			if err := w.watcher.Add(baseDir); err != nil {
-				fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Failed to watch directory %s: %v", baseDir, err)))
-				continue
-			}
-			addedDirs[baseDir] = true
-		} else {
-			// Handle standard glob pattern
-			matches, err := filepath.Glob(pattern)
-			if err != nil {
-				fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Glob error for pattern %s: %v", pattern, err)))
-				continue
-			}
-			// fmt.Println(styles.Muted.Render(fmt.Sprintf("Glob matches for %s: %v", pattern, matches)))
-
-			for _, match := range matches {
-				// Get the directory to watch
-				dir := match
-				info, err := os.Stat(match)
-				if err == nil && !info.IsDir() {
-					dir = filepath.Dir(match)
-				}
-
-				// Add directory if not already added
-				if !addedDirs[dir] {
-					if err := w.watcher.Add(dir); err != nil {
-						fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Failed to watch directory %s: %v", dir, err)))
-						continue
-					}
-					// fmt.Println(styles.Info.Render(fmt.Sprintf("Watching directory: %s", dir)))
-					addedDirs[dir] = true
-				} else {
-					// fmt.Println(styles.Muted.Render(fmt.Sprintf("Directory %s already being watched", dir)))
+		if !match.Recursive(pattern) {
+			if !added[base] {
+				if err := w.watcher.Add(base); err != nil {
+					fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Failed to watch directory %s: %v", base, err)))
+					continue
 				}
+				added[base] = true
 			}
+			continue
 		}
+
+		w.roots = append(w.roots, base)
+		w.addTree(base, added)
 	}
 
 	return w, nil
@@ -118,14 +96,25 @@ func (w *Watcher) Start() {
 				// 	return
 				// }
 
-				// Skip if event should be ignored
-				if w.shouldIgnore(event.Name) {
+				// New directories inside a recursive pattern are watched too
+				if event.Has(fsnotify.Create) && w.inRoots(event.Name) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						w.addTree(event.Name, make(map[string]bool))
+						continue
+					}
+				}
+				if event.Op == fsnotify.Chmod {
 					continue
 				}
 
 				// Normalize path to handle editor temporary files
 				normalizedPath := w.normalizePath(event.Name)
 
+				// Skip if event should be ignored
+				if !w.watched(normalizedPath) || w.shouldIgnore(normalizedPath) {
+					continue
+				}
+
 				// Send event immediately (batching handled in main loop)
 				w.events <- FileEvent{Path: normalizedPath}
 
@@ -168,17 +157,70 @@ func (w *Watcher) normalizePath(path string) string {
 	return path
 }
 
-// shouldIgnore checks if a file should be ignored
+// shouldIgnore checks if a file should be ignored: it matches an ignore
+// pattern (against the path or its basename) or the matcher skips it
 func (w *Watcher) shouldIgnore(path string) bool {
 	for _, pattern := range w.config.Ignore {
-		matched, err := filepath.Match(pattern, filepath.Base(path))
-		if err == nil && matched {
+		if match.Glob(pattern, path) || match.Glob(pattern, filepath.Base(path)) {
+			return true
+		}
+	}
+	return w.config.Matcher != nil && w.config.Matcher.SkipWatch(absolute(path))
+}
+
+// watched reports whether a file matches one of the watch patterns
+func (w *Watcher) watched(path string) bool {
+	if len(w.config.Watch) == 0 {
+		return true
+	}
+	for _, pattern := range w.config.Watch {
+		if match.Glob(pattern, path) {
 			return true
 		}
 	}
 	return false
 }
 
+// addTree watches dir and its subdirectories, skipping .git and directories the matcher skips
+func (w *Watcher) addTree(dir string, added map[string]bool) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != dir && (d.Name() == ".git" || (w.config.Matcher != nil && w.config.Matcher.SkipDir(absolute(path)))) {
+			return filepath.SkipDir
+		}
+		if added[path] {
+			return nil
+		}
+		if err := w.watcher.Add(path); err != nil {
+			fmt.Fprintln(os.Stderr, styles.CreateErrorStyle(fmt.Sprintf("Failed to watch directory %s: %v", path, err)))
+			return nil
+		}
+		added[path] = true
+		return nil
+	})
+}
+
+// inRoots reports whether path is inside a recursively watched directory
+func (w *Watcher) inRoots(path string) bool {
+	for _, root := range w.roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// absolute resolves a watched path, which is relative to the working directory
+func absolute(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
 // Close stops the watcher
 func (w *Watcher) Close() error {
 	return w.watcher.Close()
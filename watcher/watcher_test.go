@@ -73,4 +73,28 @@ func TestWatcherDetectsFileChanges(t *testing.T) {
 	case <-time.After(2 * time.Second):
 		t.Fatal("Expected file change event but got none")
 	}
-}
\ No newline at end of file
+}
+
+func TestWatcherFollowsNewDirectories(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	w, err := New(Config{Watch: []string{tmpDir + "/**/*.go"}})
+	assert.NoError(t, err)
+	defer w.Close()
+	w.Start()
+
+	nested := filepath.Join(tmpDir, "pkg", "sub")
+	assert.NoError(t, os.MkdirAll(nested, 0755))
+	time.Sleep(100 * time.Millisecond)
+
+	// Files that don't match the pattern are not reported
+	assert.NoError(t, os.WriteFile(filepath.Join(nested, "notes.txt"), []byte("x"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(nested, "main.go"), []byte("package sub"), 0644))
+
+	select {
+	case event := <-w.Events():
+		assert.Equal(t, filepath.Join(nested, "main.go"), event.Path)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected an event from the new directory")
+	}
+}